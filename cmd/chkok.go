@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 
 	chkok "github.com/farzadghanei/chkok/internal"
 )
@@ -16,38 +20,78 @@ const Version string = "0.4.0"
 // ModeHTTP run checks in http server mode
 const ModeHTTP string = "http"
 
+// ExUsage exit code for invalid command line arguments, as the flag package exits with
+const ExUsage = 2
+
 func main() {
 	var confPath string
 	var mode string
+	var outputFormat string
 	var verbose bool
 	flag.StringVar(&confPath, "conf", "/etc/chkok.yaml", "path to configuration file")
 	flag.StringVar(&mode, "mode", "cli", "running mode: cli,http")
+	flag.StringVar(&outputFormat, "output", "",
+		"output format: "+strings.Join(chkok.OutputFormats, ",")+" (default from runner config or text)")
 	flag.BoolVar(&verbose, "verbose", false, "more output, include logs")
-	flag.Parse()
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(chkok.ExOK)
+		}
+		os.Exit(failureExitCode(outputFormat, ExUsage))
+	}
 
-	os.Exit(run(confPath, mode, os.Stderr, verbose))
+	os.Exit(run(confPath, mode, outputFormat, os.Stdout, os.Stderr, verbose))
 }
 
-// run app provided with main arguments, print results to output, return exit code
-func run(confPath, mode string, output io.Writer, verbose bool) int {
+// run app provided with main arguments, print results to stdout in json and nagios output formats
+// and to stderr otherwise, return exit code. Logs and errors go to stderr, but to stdout in nagios
+// output format.
+func run(confPath, mode, outputFormat string, stdout, stderr io.Writer, verbose bool) int {
+	conf, confErr := chkok.ReadConf(confPath)
+	runnerConf, _ := chkok.GetConfRunner(&conf.Runners, mode)
+	if outputFormat != "" {
+		runnerConf.Output = &outputFormat
+	}
+	output, report := stderr, stderr
+	if *runnerConf.Output == chkok.OutputNagios {
+		output, report = stdout, stdout // nagios plugins report on stdout
+	} else if *runnerConf.Output == chkok.OutputJSON {
+		report = stdout // keep the document parsable, apart from logs
+	}
 	logger := log.New(io.Discard, "", log.Lshortfile)
 	if verbose {
-		logger.SetOutput(output)
+		logger.SetOutput(stderr)
 	}
 
-	conf, err := chkok.ReadConf(confPath)
-	if err != nil {
-		fmt.Fprintf(output, "couldn't read YAML configuration file: %v", err)
-		return chkok.ExDataErr
+	if confErr != nil {
+		fmt.Fprintf(output, "couldn't read YAML configuration file: %v", confErr)
+		return failureExitCode(*runnerConf.Output, chkok.ExDataErr)
 	}
 	checkGroups, err := chkok.CheckSuitesFromSpecSuites(conf.CheckSuites)
 	if err != nil {
 		fmt.Fprintf(output, "invalid configurations: %v", err)
+		return failureExitCode(*runnerConf.Output, chkok.ExConfig)
+	}
+	if !slices.Contains(chkok.OutputFormats, *runnerConf.Output) {
+		fmt.Fprintf(output, "invalid output format: %v", *runnerConf.Output)
 		return chkok.ExConfig
 	}
-	runnerConf, _ := chkok.GetConfRunner(&conf.Runners, mode)
+	if status := *runnerConf.ResponseWarningStatus; status < http.StatusOK || status >= http.StatusMultipleChoices {
+		fmt.Fprintf(output, "invalid response warning status, want a 2xx status got: %v", status)
+		return failureExitCode(*runnerConf.Output, chkok.ExConfig)
+	}
 	if mode == ModeHTTP {
 		return chkok.RunModeHTTP(&checkGroups, &runnerConf, logger)
 	}
-	return chkok.RunModeCLI(&checkGroups, &runnerConf, output, logger)
+	return chkok.RunModeCLI(&checkGroups, &runnerConf, report, logger)
+}
+
+// failureExitCode returns the exit code of failures before running the checks, which is
+// UNKNOWN in nagios output format as expected from nagios plugins
+func failureExitCode(outputFormat string, code int) int {
+	if outputFormat == chkok.OutputNagios {
+		return chkok.NagiosUnknown
+	}
+	return code
 }
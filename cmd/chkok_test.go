@@ -4,12 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	chkok "github.com/farzadghanei/chkok/internal"
 )
 
 const (
@@ -22,10 +26,94 @@ func TestRunCli(t *testing.T) {
 	baseDir, _ := filepath.Abs(filepath.Dir(cwd))
 	writer := bufio.NewWriter(&buf)
 	var confPath = filepath.Join(baseDir, "examples", "test.yaml")
-	got := run(confPath, "cli", writer, false)
+	got := run(confPath, "cli", "", writer, writer, false)
+	if got != 0 {
+		t.Errorf("want exit code 0, got %v. output: %v", got, buf.String())
+	}
+}
+
+func TestRunCliJSON(t *testing.T) {
+	var buf bytes.Buffer
+	cwd, _ := os.Getwd()
+	baseDir, _ := filepath.Abs(filepath.Dir(cwd))
+	var confPath = filepath.Join(baseDir, "examples", "test.yaml")
+	var logs bytes.Buffer
+	got := run(confPath, "cli", "json", &buf, &logs, true)
 	if got != 0 {
 		t.Errorf("want exit code 0, got %v. output: %v", got, buf.String())
 	}
+	var report chkok.Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("want json output, got err %v. output: %v", err, buf.String())
+	}
+	if !report.IsOK || report.Totals.Total != 4 || report.Totals.Passed != 4 {
+		t.Errorf("want 4 passed checks in json report, got %+v", report.Totals)
+	}
+	if len(report.Checks) != 4 || report.Checks[0].Suite != "default" || report.Checks[0].Status != "done" {
+		t.Errorf("want 4 done checks starting with default suite in json report, got %+v", report.Checks)
+	}
+	if logs.Len() == 0 {
+		t.Errorf("want verbose logs on stderr, got none")
+	}
+}
+
+func TestRunCliNagios(t *testing.T) {
+	var buf bytes.Buffer
+	cwd, _ := os.Getwd()
+	baseDir, _ := filepath.Abs(filepath.Dir(cwd))
+	var confPath = filepath.Join(baseDir, "examples", "test.yaml")
+	got := run(confPath, "cli", "nagios", &buf, io.Discard, false)
+	if got != chkok.NagiosOK {
+		t.Errorf("want exit code %v, got %v. output: %v", chkok.NagiosOK, got, buf.String())
+	}
+	if !strings.HasPrefix(buf.String(), "CHKOK OK - 4 checks passed | ") {
+		t.Errorf("want nagios ok status line with perfdata, got %v", buf.String())
+	}
+}
+
+func TestRunCliNagiosFromConf(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	confPath := filepath.Join(t.TempDir(), "nagios.yaml")
+	conf := "runners:\n  cli:\n    output: nagios\ncheck_suites:\n  default:\n    - type: file\n      path: " +
+		confPath + "\n"
+	if err := os.WriteFile(confPath, []byte(conf), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	got := run(confPath, "cli", "", &stdout, &stderr, false)
+	if got != chkok.NagiosOK {
+		t.Errorf("want exit code %v, got %v. output: %v", chkok.NagiosOK, got, stdout.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "CHKOK OK - 1 checks passed") || stderr.Len() != 0 {
+		t.Errorf("want nagios status line on stdout only, got stdout %q stderr %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunCliNagiosInvalidConf(t *testing.T) {
+	var buf bytes.Buffer
+	confPath := filepath.Join(t.TempDir(), "invalid.yaml")
+	conf := "check_suites:\n  default:\n    - type: unknown\n"
+	if err := os.WriteFile(confPath, []byte(conf), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	got := run(confPath, "cli", "nagios", &buf, io.Discard, false)
+	if got != chkok.NagiosUnknown {
+		t.Errorf("want exit code %v, got %v. output: %v", chkok.NagiosUnknown, got, buf.String())
+	}
+	got = run(filepath.Join(t.TempDir(), "missing.yaml"), "cli", "nagios", &buf, io.Discard, false)
+	if got != chkok.NagiosUnknown {
+		t.Errorf("want exit code %v for missing config, got %v. output: %v", chkok.NagiosUnknown, got, buf.String())
+	}
+}
+
+func TestRunCliInvalidOutput(t *testing.T) {
+	var buf bytes.Buffer
+	cwd, _ := os.Getwd()
+	baseDir, _ := filepath.Abs(filepath.Dir(cwd))
+	var confPath = filepath.Join(baseDir, "examples", "test.yaml")
+	got := run(confPath, "cli", "xml", io.Discard, &buf, false)
+	if got != chkok.ExConfig {
+		t.Errorf("want exit code %v, got %v. output: %v", chkok.ExConfig, got, buf.String())
+	}
 }
 
 func TestRunHttp(t *testing.T) {
@@ -36,7 +124,7 @@ func TestRunHttp(t *testing.T) {
 	var confPath = filepath.Join(baseDir, "examples", "test-http.yaml")
 
 	go func() { // run the server in a goroutine
-		run(confPath, ModeHTTP, writer, false)
+		run(confPath, ModeHTTP, "", writer, writer, false)
 	}()
 
 	// Test the runner via an HTTP request
@@ -1,10 +1,22 @@
 package chkok
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/md5" //nolint: gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -21,8 +33,27 @@ const (
 	StatusStopped
 	// StatusDone is when a check successfully ran
 	StatusDone
+	// StatusSkipped is when a check was not run because an earlier check in the suite failed
+	StatusSkipped
 )
 
+// String returns the name of the status
+func (s Status) String() string {
+	switch s {
+	case StatusUnknown:
+		return "unknown"
+	case StatusRunning:
+		return "running"
+	case StatusStopped:
+		return "stopped"
+	case StatusDone:
+		return "done"
+	case StatusSkipped:
+		return "skipped"
+	}
+	return fmt.Sprintf("status(%d)", uint8(s))
+}
+
 // FileType is the type of a file resources, use Type* contants
 type FileType uint8
 
@@ -35,10 +66,30 @@ const (
 	TypeDir
 )
 
-// Result is the results of a Check
+// Severity is how a failed check affects the outcome of the run, use Severity* constants
+type Severity uint8
+
+const (
+	// SeverityCritical is when a failed check fails the run
+	SeverityCritical Severity = iota
+	// SeverityWarning is when a failed check only warns, without failing the run
+	SeverityWarning
+)
+
+// Result is the results of a Check.
+// Warnings are issues that don't fail the check, so a check with warnings is still OK.
 type Result struct {
-	IsOK   bool
-	Issues []error
+	IsOK     bool
+	Issues   []error
+	Warnings []error
+	Metrics  []Metric
+}
+
+// Metric is a measurement taken by a check run, like a file size
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"` // "B" for bytes, "s" for seconds, empty for counts
 }
 
 // Check is the interface that all checks implement
@@ -48,6 +99,7 @@ type Check interface {
 	Run() Result
 	Result() Result
 	Status() Status
+	Duration() time.Duration
 }
 
 // TimedCheck is the interface for checks that accept a timeout
@@ -56,14 +108,31 @@ type TimedCheck interface {
 	SetTimeout(t time.Duration)
 }
 
-// CheckSuites is list of checks, grouped by suite name
-type CheckSuites map[string][]Check
+// CheckSuite is a group of checks that run sequentially
+type CheckSuite struct {
+	Checks        []Check
+	StopOnFailure bool // skip the remaining checks after a check fails
+}
+
+// CheckSuites is list of check suites, grouped by suite name
+type CheckSuites map[string]CheckSuite
+
+// checkHooks is implemented by checks embedding baseCheck, so suites and the runner can update them
+type checkHooks interface {
+	setSuite(suite string)
+	setDuration(duration time.Duration)
+	setSeverity(severity Severity)
+	applySeverity() Result
+	skip(reason error)
+}
 
 type baseCheck struct {
-	suite  string
-	name   string
-	status Status
-	result Result
+	suite    string
+	name     string
+	status   Status
+	result   Result
+	duration time.Duration
+	severity Severity
 }
 
 func (bc *baseCheck) Suite() string {
@@ -82,35 +151,203 @@ func (bc *baseCheck) Status() Status {
 	return bc.status
 }
 
-// CheckFile checks for file/dir existence/type/uid/gid/size/file count
+// Duration returns how long the last run of the check took
+func (bc *baseCheck) Duration() time.Duration {
+	return bc.duration
+}
+
+func (bc *baseCheck) setSuite(suite string) {
+	bc.suite = suite
+}
+
+func (bc *baseCheck) setDuration(duration time.Duration) {
+	bc.duration = duration
+}
+
+func (bc *baseCheck) setSeverity(severity Severity) {
+	bc.severity = severity
+}
+
+// applySeverity turns issues of a failed check with warning severity into warnings, returning the result
+func (bc *baseCheck) applySeverity() Result {
+	if bc.severity == SeverityWarning && bc.status == StatusDone && !bc.result.IsOK {
+		bc.result.Warnings = append(bc.result.Warnings, bc.result.Issues...)
+		bc.result.Issues = []error{}
+		bc.result.IsOK = true
+	}
+	return bc.result
+}
+
+// skip marks the check as skipped, with the reason as the only issue
+func (bc *baseCheck) skip(reason error) {
+	bc.status = StatusSkipped
+	bc.result = Result{IsOK: false, Issues: []error{reason}}
+}
+
+// DefaultMaxReadSize is the default max number of bytes read from files to check their content
+const DefaultMaxReadSize = 1 << 20
+
+// DefaultDirScanTimeout is the default time budget of recursive directory scans
+const DefaultDirScanTimeout = 5 * time.Second
+
+// DefaultDialTimeout is the default timeout of dial checks, connecting and waiting for a reply
+const DefaultDialTimeout = 5 * time.Second
+
+// maxDialReplySize is the max number of bytes read from the reply of dial checks (max udp payload)
+const maxDialReplySize = 64 * 1024
+
+// maxDialReplyReport is the max number of bytes of an unexpected dial reply included in the issues
+const maxDialReplyReport = 128
+
+// modeBitsMask is the mask of the permission bits, including setuid/setgid/sticky bits
+const modeBitsMask = 0o7777
+
+// CheckFile checks for file/dir existence/type/uid/gid/mode/size/file count
 type CheckFile struct {
 	baseCheck
 	path         string
 	fileType     FileType
 	uid          int32 // -1 to skip
 	gid          int32 // -1 to skip
+	mode         int32 // -1 to skip, exact permission bits
+	minMode      int32 // -1 to skip, permission bits that should be set
+	maxMode      int32 // -1 to skip, permission bits that could be set
 	absent       bool
 	minSize      int32 // -1 to skip
 	maxSize      int64 // -1 to skip
 	minFileCount int   // -1 to skip
 	maxFileCount int   // -1 to skip
+	maxTotalSize int64 // -1 to skip, total size of the files in a directory
+	// directory files are counted recursively up to max depth (0 for no limit) within the timeout (0 for no limit),
+	// only the files with names matching the include glob pattern (empty to include all)
+	recursive bool
+	maxDepth  int
+	include   string
+	timeout   time.Duration
+	// warning thresholds, only warn (check still passes) when crossed. -1 to skip
+	warnMinSize      int64
+	warnMaxSize      int64
+	warnMinFileCount int
+	warnMaxFileCount int
+	// content of regular files matched line by line, failing for files larger than maxReadSize bytes
+	contains    []string
+	notContains []string
+	regex       *regexp.Regexp // nil to skip
+	maxReadSize int64
+	// expected digests of regular files, from the check or a checksum file (sha256sum format)
+	digests      []FileDigest
+	checksumFile string // empty to skip
+	// age of the file (and newest entry of a directory) based on ageOf, 0 to skip
+	ageOf        FileTime
+	minAge       time.Duration
+	maxAge       time.Duration
+	newestMaxAge time.Duration
+}
+
+// FileTime is the timestamp of a file used for its age, use FileTime* constants
+type FileTime uint8
+
+const (
+	// FileTimeModify is the last modification time (mtime) of a file
+	FileTimeModify FileTime = iota
+	// FileTimeChange is the last status change time (ctime) of a file
+	FileTimeChange
+	// FileTimeAccess is the last access time (atime) of a file
+	FileTimeAccess
+)
+
+// ParseFileTime returns the FileTime of the name, "mtime" (default if empty), "ctime" or "atime"
+func ParseFileTime(name string) (FileTime, error) {
+	switch strings.ToLower(name) {
+	case "", "mtime":
+		return FileTimeModify, nil
+	case "ctime":
+		return FileTimeChange, nil
+	case "atime":
+		return FileTimeAccess, nil
+	}
+	return FileTimeModify, fmt.Errorf("invalid file time %q, want mtime, ctime or atime", name)
+}
+
+// String returns the name of the file time
+func (ft FileTime) String() string {
+	switch ft {
+	case FileTimeChange:
+		return "ctime"
+	case FileTimeAccess:
+		return "atime"
+	}
+	return "mtime"
+}
+
+// of returns the file time from the file stat
+func (ft FileTime) of(fstat *syscall.Stat_t) time.Time {
+	atime, mtime, ctime := statTimes(fstat)
+	switch ft {
+	case FileTimeChange:
+		return ctime
+	case FileTimeAccess:
+		return atime
+	}
+	return mtime
 }
 
-// NewCheckFile returns a new checkFile without a uid/gid/size/file count checks
+// FileDigest is the expected digest (hex encoded) of a file with a hash algorithm
+type FileDigest struct {
+	Algorithm string // md5, sha256 or sha512
+	Digest    string
+}
+
+// digestHashes are the hash functions of the supported file digest algorithms
+var digestHashes = map[string]func() hash.Hash{"md5": md5.New, "sha256": sha256.New, "sha512": sha512.New}
+
+// NewFileDigest returns the FileDigest of the algorithm, validating the hex encoded digest
+func NewFileDigest(algorithm, digest string) (FileDigest, error) {
+	newHash, ok := digestHashes[algorithm]
+	if !ok {
+		return FileDigest{}, fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != newHash().Size() {
+		return FileDigest{}, fmt.Errorf("invalid %v digest %q", algorithm, digest)
+	}
+	return FileDigest{Algorithm: algorithm, Digest: digest}, nil
+}
+
+// NewCheckFile returns a new checkFile without a uid/gid/mode/size/file count checks
 func NewCheckFile(path string) *CheckFile {
 	return &CheckFile{
-		path:         path,
-		fileType:     TypeAny,
-		uid:          -1,
-		gid:          -1,
-		absent:       false,
-		minSize:      -1,
-		maxSize:      -1,
-		minFileCount: -1,
-		maxFileCount: -1,
+		path:             path,
+		fileType:         TypeAny,
+		uid:              -1,
+		gid:              -1,
+		mode:             -1,
+		minMode:          -1,
+		maxMode:          -1,
+		absent:           false,
+		minSize:          -1,
+		maxSize:          -1,
+		minFileCount:     -1,
+		maxFileCount:     -1,
+		warnMinSize:      -1,
+		warnMaxSize:      -1,
+		warnMinFileCount: -1,
+		warnMaxFileCount: -1,
+		maxTotalSize:     -1,
+		maxReadSize:      DefaultMaxReadSize,
 	}
 }
 
+// GetTimeout returns the timeout of the check
+func (chk *CheckFile) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the timeout of the check, used as the time budget to scan directories
+func (chk *CheckFile) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
 func (chk *CheckFile) typeString() string {
 	switch chk.fileType {
 	case TypeFile:
@@ -153,9 +390,8 @@ func (chk *CheckFile) Run() Result {
 		if !finfo.IsDir() {
 			chk.result.IsOK = false
 			chk.result.Issues = append(chk.result.Issues, errors.New("is not a directory"))
-		} else if chk.minFileCount > -1 || chk.maxFileCount > -1 {
-			// Only check file counts if it's a directory and we have count constraints
-			chk.checkFileCount(&chk.result)
+		} else {
+			chk.checkDirEntries(&chk.result)
 		}
 	case TypeFile:
 		if !finfo.Mode().IsRegular() {
@@ -165,11 +401,53 @@ func (chk *CheckFile) Run() Result {
 	}
 
 	chk.checkUIDGID(fstat, &chk.result)
+	chk.checkMode(fstat, &chk.result)
 	chk.checkSize(finfo.Size(), &chk.result)
-	chk.status = StatusDone
+	if chk.minAge > 0 || chk.maxAge > 0 {
+		chk.checkAge(fstat, &chk.result)
+	}
+	if finfo.Mode().IsRegular() {
+		chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "size", Value: float64(finfo.Size()), Unit: "B"})
+		chk.checkRegularFile(&chk.result)
+	}
+	if chk.status == StatusRunning { // not stopped by the timeout
+		chk.status = StatusDone
+	}
 	return chk.result
 }
 
+// checkDirEntries checks the entries of the directory (file count, total size, newest entry age)
+// and updates the provided result
+func (chk *CheckFile) checkDirEntries(result *Result) {
+	if chk.hasFileCountLimits() || chk.maxTotalSize > -1 {
+		// Only scan the directory if we have count or size constraints
+		count, totalSize, err := chk.scanDir()
+		if err != nil {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf("failed to count files: %v", err))
+			if errors.Is(err, errDirScanTimeout) {
+				chk.status = StatusStopped
+			}
+		} else {
+			chk.checkFileCount(count, result)
+			chk.checkTotalSize(totalSize, result)
+		}
+	}
+	if chk.newestMaxAge > 0 {
+		chk.checkNewestEntryAge(result)
+	}
+}
+
+// checkRegularFile checks the content and digests of the regular file and updates the provided result
+func (chk *CheckFile) checkRegularFile(result *Result) {
+	if len(chk.contains) > 0 || len(chk.notContains) > 0 || chk.regex != nil {
+		chk.checkContent(result)
+	}
+	if len(chk.digests) > 0 || chk.checksumFile != "" {
+		chk.checkDigests(result)
+	}
+}
+
 // CheckFile.checkUIDGID checks for file uid/gid attrs updates the provided result
 func (chk *CheckFile) checkUIDGID(fstat *syscall.Stat_t, result *Result) {
 	if chk.uid > -1 {
@@ -193,68 +471,357 @@ func (chk *CheckFile) checkUIDGID(fstat *syscall.Stat_t, result *Result) {
 	}
 }
 
+// checkMode checks for file permission bits (including setuid/setgid/sticky) and updates the provided result
+func (chk *CheckFile) checkMode(fstat *syscall.Stat_t, result *Result) {
+	if chk.mode < 0 && chk.minMode < 0 && chk.maxMode < 0 {
+		return
+	}
+	if fstat == nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("check for file mode is not supported on this system"))
+		return
+	}
+	mode := statMode(fstat) & modeBitsMask
+	if chk.mode > -1 && mode != uint32(chk.mode) { //nolint: gosec
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("mode mismatch want %04o got %04o", chk.mode, mode))
+	}
+	if chk.minMode > -1 && mode&uint32(chk.minMode) != uint32(chk.minMode) { //nolint: gosec
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"mode is missing permissions, want at least %04o got %04o", chk.minMode, mode))
+	}
+	if chk.maxMode > -1 && mode&^uint32(chk.maxMode) != 0 { //nolint: gosec
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"mode is too permissive, want at most %04o got %04o", chk.maxMode, mode))
+	}
+}
+
 // checkSize checks for file min/max size and updates the provided result
 func (chk *CheckFile) checkSize(size int64, result *Result) {
 	if chk.minSize > -1 && size <= int64(chk.minSize) {
 		result.IsOK = false
 		result.Issues = append(result.Issues, fmt.Errorf(
 			"file too small, size %v is less than min size %v", size, chk.minSize))
+	} else if chk.warnMinSize > -1 && size <= chk.warnMinSize {
+		result.Warnings = append(result.Warnings, fmt.Errorf(
+			"file is small, size %v is less than warning min size %v", size, chk.warnMinSize))
 	}
 	if chk.maxSize > -1 && size >= chk.maxSize {
 		result.IsOK = false
 		result.Issues = append(result.Issues, fmt.Errorf(
 			"file too large, size %v is more than max size %v", size, chk.maxSize))
+	} else if chk.warnMaxSize > -1 && size >= chk.warnMaxSize {
+		result.Warnings = append(result.Warnings, fmt.Errorf(
+			"file is large, size %v is more than warning max size %v", size, chk.warnMaxSize))
 	}
 }
 
-// countFilesInDir counts the number of files in a directory
-func countFilesInDir(dirPath string) (int, error) {
-	entries, err := os.ReadDir(dirPath)
+// errDirScanTimeout is the error when scanning a directory takes longer than the check timeout
+var errDirScanTimeout = errors.New("directory scan timed out")
+
+// scanDir returns the number and total size of entries in the directory matching the include pattern.
+// Recursive scans count only the files (not directories) up to the max depth.
+func (chk *CheckFile) scanDir() (count int, totalSize int64, err error) {
+	var deadline time.Time
+	if chk.timeout > 0 {
+		deadline = time.Now().Add(chk.timeout)
+	}
+	err = filepath.WalkDir(chk.path, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return fmt.Errorf("%w after %v", errDirScanTimeout, chk.timeout)
+		}
+		if path == chk.path {
+			return nil
+		}
+		counted, next := chk.scanDirEntry(path, entry)
+		if counted {
+			count++
+			if chk.maxTotalSize > -1 && entry.Type().IsRegular() {
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				totalSize += info.Size()
+			}
+		}
+		return next
+	})
+	return count, totalSize, err
+}
+
+// scanDirEntry returns if the entry is counted in the directory scan, and
+// filepath.SkipDir if the scan should not continue in the entry directory
+func (chk *CheckFile) scanDirEntry(path string, entry os.DirEntry) (bool, error) {
+	var next error
+	if entry.IsDir() {
+		if !chk.recursive {
+			next = filepath.SkipDir // count the directory, but not its entries
+		} else if chk.maxDepth > 0 && dirDepth(chk.path, path) >= chk.maxDepth {
+			return false, filepath.SkipDir
+		} else {
+			return false, nil
+		}
+	}
+	if matched, _ := filepath.Match(chk.include, entry.Name()); chk.include != "" && !matched {
+		return false, next
+	}
+	return true, next
+}
+
+// dirDepth returns the depth of the path in the root directory, 1 for the root entries
+func dirDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
 	if err != nil {
-		return 0, err
+		return 0
 	}
-	return len(entries), nil
+	return strings.Count(rel, string(filepath.Separator)) + 1
 }
 
-// checkFileCount checks for directory min/max file count and updates the provided result
-func (chk *CheckFile) checkFileCount(result *Result) {
-	count, err := countFilesInDir(chk.path)
+// checkTotalSize checks for directory max total size of files and updates the provided result
+func (chk *CheckFile) checkTotalSize(totalSize int64, result *Result) {
+	if chk.maxTotalSize < 0 {
+		return
+	}
+	result.Metrics = append(result.Metrics, Metric{Name: "total_size", Value: float64(totalSize), Unit: "B"})
+	if totalSize > chk.maxTotalSize {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"directory files too large, total size %v is more than max total size %v", totalSize, chk.maxTotalSize))
+	}
+}
+
+// checkContent checks the file lines contain or don't contain the strings and match the regex,
+// and updates the provided result. Files larger than max read size fail without checking the content.
+func (chk *CheckFile) checkContent(result *Result) {
+	file, err := os.Open(chk.path)
+	if err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, err)
+		return
+	}
+	defer file.Close()
+	found := make([]bool, len(chk.contains))
+	unwanted := make([]bool, len(chk.notContains))
+	matched := chk.regex == nil
+	scanner := bufio.NewScanner(io.LimitReader(file, chk.maxReadSize))
+	scanner.Buffer(make([]byte, 0, min(chk.maxReadSize, bufio.MaxScanTokenSize)), int(chk.maxReadSize)+1) //nolint: gosec
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		for i, want := range chk.contains {
+			found[i] = found[i] || bytes.Contains(line, []byte(want))
+		}
+		for i, notWant := range chk.notContains {
+			unwanted[i] = unwanted[i] || bytes.Contains(line, []byte(notWant))
+		}
+		matched = matched || chk.regex.Match(line)
+	}
+	if err = scanner.Err(); err == nil {
+		if n, _ := file.Read(make([]byte, 1)); n > 0 {
+			err = fmt.Errorf("file is larger than max read size %v", chk.maxReadSize)
+		}
+	}
+	if err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("failed to read file content: %w", err))
+		return
+	}
+	for i, want := range chk.contains {
+		if !found[i] {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf("file doesn't contain %q", want))
+		}
+	}
+	for i, notWant := range chk.notContains {
+		if unwanted[i] {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf("file contains %q", notWant))
+		}
+	}
+	if !matched {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("file doesn't match regex %q", chk.regex))
+	}
+}
+
+// checkDigests checks the file digests match the expected digests and updates the provided result
+func (chk *CheckFile) checkDigests(result *Result) {
+	digests := slices.Clone(chk.digests)
+	if chk.checksumFile != "" {
+		digest, err := readChecksumFile(chk.checksumFile, chk.path)
+		if err != nil {
+			result.IsOK = false
+			result.Issues = append(result.Issues, err)
+			return
+		}
+		digests = append(digests, digest)
+	}
+	hashes := map[string]hash.Hash{}
+	writers := []io.Writer{}
+	for _, digest := range digests {
+		if _, ok := hashes[digest.Algorithm]; !ok {
+			hashes[digest.Algorithm] = digestHashes[digest.Algorithm]()
+			writers = append(writers, hashes[digest.Algorithm])
+		}
+	}
+	file, err := os.Open(chk.path)
+	if err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, err)
+		return
+	}
+	defer file.Close()
+	if _, err = io.Copy(io.MultiWriter(writers...), file); err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("failed to read file: %v", err))
+		return
+	}
+	for _, digest := range digests {
+		if got := hex.EncodeToString(hashes[digest.Algorithm].Sum(nil)); got != digest.Digest {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"%v digest mismatch want %v got %v", digest.Algorithm, digest.Digest, got))
+		}
+	}
+}
+
+// readChecksumFile returns the digest of the path from a checksum file in sha256sum format
+// (also md5sum and sha512sum), with paths absolute or relative to the checksum file directory
+func readChecksumFile(checksumFile, path string) (FileDigest, error) {
+	file, err := os.Open(checksumFile)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	defer file.Close()
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		digest, entry, found := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		entry = strings.TrimPrefix(strings.TrimLeft(entry, " "), "*") // "*" is for binary mode
+		if !found || strings.HasPrefix(digest, "#") {
+			continue
+		}
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(filepath.Dir(checksumFile), entry)
+		}
+		if entry, err = filepath.Abs(entry); err != nil || entry != absPath {
+			continue
+		}
+		for algorithm, newHash := range digestHashes {
+			if len(digest) == newHash().Size()*2 {
+				return NewFileDigest(algorithm, digest)
+			}
+		}
+		return FileDigest{}, fmt.Errorf("checksum file %v has invalid digest %q for %v", checksumFile, digest, path)
+	}
+	if err = scanner.Err(); err != nil {
+		return FileDigest{}, err
+	}
+	return FileDigest{}, fmt.Errorf("checksum file %v has no digest for %v", checksumFile, path)
+}
+
+// checkAge checks the file min/max age and updates the provided result
+func (chk *CheckFile) checkAge(fstat *syscall.Stat_t, result *Result) {
+	age := time.Since(chk.ageOf.of(fstat))
+	result.Metrics = append(result.Metrics, Metric{Name: "age", Value: age.Seconds(), Unit: "s"})
+	if chk.maxAge > 0 && age > chk.maxAge {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"file too old, %v age %v is more than max age %v", chk.ageOf, age.Truncate(time.Second), chk.maxAge))
+	}
+	if chk.minAge > 0 && age < chk.minAge {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"file too new, %v age %v is less than min age %v", chk.ageOf, age.Truncate(time.Second), chk.minAge))
+	}
+}
+
+// checkNewestEntryAge checks the newest entry of the directory is not older than
+// the max age and updates the provided result
+func (chk *CheckFile) checkNewestEntryAge(result *Result) {
+	entries, err := os.ReadDir(chk.path)
 	if err != nil {
 		result.IsOK = false
-		result.Issues = append(result.Issues, fmt.Errorf("failed to count files: %v", err))
+		result.Issues = append(result.Issues, fmt.Errorf("failed to read directory: %v", err))
 		return
 	}
+	var newest time.Time
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil { // entry is removed since reading the directory
+			continue
+		}
+		if entryTime := chk.ageOf.of(info.Sys().(*syscall.Stat_t)); entryTime.After(newest) {
+			newest = entryTime
+		}
+	}
+	if newest.IsZero() {
+		result.IsOK = false
+		result.Issues = append(result.Issues, errors.New("directory has no entries"))
+		return
+	}
+	age := time.Since(newest)
+	result.Metrics = append(result.Metrics, Metric{Name: "newest_age", Value: age.Seconds(), Unit: "s"})
+	if age > chk.newestMaxAge {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("newest directory entry too old, %v age %v is more than max age %v",
+			chk.ageOf, age.Truncate(time.Second), chk.newestMaxAge))
+	}
+}
+
+// hasFileCountLimits returns true if any of the min/max file count limits (or their warnings) are set
+func (chk *CheckFile) hasFileCountLimits() bool {
+	return chk.minFileCount > -1 || chk.maxFileCount > -1 || chk.warnMinFileCount > -1 || chk.warnMaxFileCount > -1
+}
+
+// checkFileCount checks for directory min/max file count and updates the provided result
+func (chk *CheckFile) checkFileCount(count int, result *Result) {
+	result.Metrics = append(result.Metrics, Metric{Name: "file_count", Value: float64(count)})
 
 	if chk.minFileCount > -1 && count < chk.minFileCount {
 		result.IsOK = false
 		result.Issues = append(result.Issues, fmt.Errorf(
 			"directory contains too few files, found %v but minimum is %v", count, chk.minFileCount))
+	} else if chk.warnMinFileCount > -1 && count < chk.warnMinFileCount {
+		result.Warnings = append(result.Warnings, fmt.Errorf(
+			"directory contains few files, found %v but warning minimum is %v", count, chk.warnMinFileCount))
 	}
 
 	if chk.maxFileCount > -1 && count > chk.maxFileCount {
 		result.IsOK = false
 		result.Issues = append(result.Issues, fmt.Errorf(
 			"directory contains too many files, found %v but maximum is %v", count, chk.maxFileCount))
+	} else if chk.warnMaxFileCount > -1 && count > chk.warnMaxFileCount {
+		result.Warnings = append(result.Warnings, fmt.Errorf(
+			"directory contains many files, found %v but warning maximum is %v", count, chk.warnMaxFileCount))
 	}
 }
 
-// CheckDial checks for a net resource by dialing
+// CheckDial checks for a net resource by dialing, optionally sending a payload and
+// validating the reply. Dialing udp networks sends no packets, so for udp the Send payload
+// is sent and a reply is expected from the address.
 type CheckDial struct {
 	baseCheck
-	Network string
-	Address string
-	Absent  bool
-	timeout time.Duration
+	Network     string
+	Address     string
+	Absent      bool
+	Send        []byte         // payload sent after connecting, udp networks wait for a reply to it
+	Expect      []byte         // expected to be in the reply (e.g. a banner), nil to skip
+	ExpectRegex *regexp.Regexp // expected to match the reply, nil to skip
+	timeout     time.Duration
 }
 
 // NewCheckDial returns a checkDial for local http availability by default
 func NewCheckDial() *CheckDial {
-	timeout, err := time.ParseDuration("5s")
-	if err != nil {
-		panic("err creating check dial default timeout")
-	}
 	chk := CheckDial{Network: "tcp", Address: "127.0.0.1:80", Absent: false}
-	chk.SetTimeout(timeout)
+	chk.SetTimeout(DefaultDialTimeout)
 	return &chk
 }
 
@@ -285,7 +852,12 @@ func (chk *CheckDial) Run() Result {
 	start := time.Now()
 	chk.result = Result{IsOK: true, Issues: []error{}}
 	conn, err := net.DialTimeout(chk.Network, chk.Address, chk.timeout)
-	if err != nil { // no connection
+	var reply []byte
+	if err == nil {
+		defer conn.Close()
+		reply, err = chk.exchange(conn, start)
+	}
+	if err != nil { // no connection, or no reply
 		if chk.Absent {
 			chk.status = StatusDone
 			return chk.result
@@ -295,7 +867,11 @@ func (chk *CheckDial) Run() Result {
 		chk.status = StatusDone
 		return chk.result
 	}
-	defer conn.Close()
+	if !chk.replyMatches(reply) {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("unexpected reply from %v: %q",
+			chk.Address, truncateBytes(reply, maxDialReplyReport)))
+	}
 	elapsed := time.Since(start)
 	if elapsed > chk.timeout {
 		chk.status = StatusStopped
@@ -306,3 +882,60 @@ func (chk *CheckDial) Run() Result {
 	}
 	return chk.result
 }
+
+// exchange sends the payload over the connection, and reads the reply if expected until
+// it matches the expectations, the connection is closed, or the check times out
+func (chk *CheckDial) exchange(conn net.Conn, start time.Time) ([]byte, error) {
+	udp := isUDPNetwork(chk.Network)
+	timeout := chk.timeout
+	if timeout <= 0 { // never wait forever on a silent peer
+		timeout = DefaultDialTimeout
+	}
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return nil, err
+	}
+	if udp || len(chk.Send) > 0 {
+		if _, err := conn.Write(chk.Send); err != nil {
+			return nil, fmt.Errorf("failed to send to %v: %w", chk.Address, err)
+		}
+	}
+	if !udp && chk.Expect == nil && chk.ExpectRegex == nil {
+		return nil, nil
+	}
+	reply := make([]byte, 0, maxDialReplySize)
+	for len(reply) < cap(reply) {
+		n, err := conn.Read(reply[len(reply):cap(reply)])
+		reply = reply[:len(reply)+n]
+		if err != nil {
+			if len(reply) == 0 {
+				return nil, fmt.Errorf("no reply from %v: %w", chk.Address, err)
+			}
+			return reply, nil // validated by the caller
+		}
+		if udp || chk.replyMatches(reply) { // a udp reply is a single datagram
+			return reply, nil
+		}
+	}
+	return reply, nil
+}
+
+// replyMatches returns if the reply contains the expected bytes and matches the expected regex
+func (chk *CheckDial) replyMatches(reply []byte) bool {
+	if chk.Expect != nil && !bytes.Contains(reply, chk.Expect) {
+		return false
+	}
+	return chk.ExpectRegex == nil || chk.ExpectRegex.Match(reply)
+}
+
+// truncateBytes returns the first size bytes of the data
+func truncateBytes(data []byte, size int) []byte {
+	if len(data) > size {
+		return data[:size]
+	}
+	return data
+}
+
+// isUDPNetwork returns if the dial network is udp, udp4 or udp6
+func isUDPNetwork(network string) bool {
+	return strings.HasPrefix(network, "udp")
+}
@@ -0,0 +1,134 @@
+package chkok
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"time"
+)
+
+// CheckCertFile checks for a PEM X.509 certificate file, its expiry, subject/issuer/SANs,
+// and optionally if a private key file matches the certificate. File attributes are
+// checked same as CheckFile.
+type CheckCertFile struct {
+	CheckFile
+	MinValidFor time.Duration // min duration the certificate should be valid for, 0 to skip
+	Subject     string        // expected subject common name or distinguished name, empty to skip
+	Issuer      string        // expected issuer common name or distinguished name, empty to skip
+	SANs        []string      // subject alternative names (DNS, IP or email) the certificate should have
+	KeyFile     string        // path to the PEM private key that should match the certificate, empty to skip
+}
+
+// NewCheckCertFile returns a new CheckCertFile for a regular file without expectations
+func NewCheckCertFile(path string) *CheckCertFile {
+	chk := CheckCertFile{CheckFile: *NewCheckFile(path)}
+	chk.fileType = TypeFile
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckCertFile) Name() string {
+	return fmt.Sprintf("cert_file:%v", chk.path)
+}
+
+// Run runs the check
+func (chk *CheckCertFile) Run() Result {
+	chk.CheckFile.Run()
+	if chk.absent || !chk.result.IsOK {
+		return chk.result
+	}
+
+	contents, err := os.ReadFile(chk.path)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		return chk.result
+	}
+	cert, err := parsePEMCertificate(contents)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		return chk.result
+	}
+
+	daysToExpiry := time.Until(cert.NotAfter).Hours() / 24 // negative when expired
+	chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "days_to_expiry", Value: daysToExpiry})
+	checkCertExpiry(cert, chk.MinValidFor, &chk.result)
+	chk.checkNames(cert, &chk.result)
+	if chk.KeyFile != "" {
+		chk.checkKeyFile(contents, &chk.result)
+	}
+	return chk.result
+}
+
+// checkNames checks the certificate subject, issuer and SANs and updates the provided result
+func (chk *CheckCertFile) checkNames(cert *x509.Certificate, result *Result) {
+	if chk.Subject != "" && chk.Subject != cert.Subject.CommonName && chk.Subject != cert.Subject.String() {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"certificate subject mismatch want %q got %q", chk.Subject, cert.Subject.String()))
+	}
+	if chk.Issuer != "" && chk.Issuer != cert.Issuer.CommonName && chk.Issuer != cert.Issuer.String() {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"certificate issuer mismatch want %q got %q", chk.Issuer, cert.Issuer.String()))
+	}
+	sans := certSANs(cert)
+	for _, name := range chk.SANs {
+		if !slices.Contains(sans, certSANIP(name)) {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"certificate is missing subject alternative name %q, got %v", name, sans))
+		}
+	}
+}
+
+// checkKeyFile checks the private key file matches the certificate and updates the provided result
+func (chk *CheckCertFile) checkKeyFile(certPEM []byte, result *Result) {
+	keyPEM, err := os.ReadFile(chk.KeyFile)
+	if err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("failed to read key file: %v", err))
+		return
+	}
+	if _, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("key file %v doesn't match the certificate: %v", chk.KeyFile, err))
+	}
+}
+
+// parsePEMCertificate returns the first certificate in the PEM contents
+func parsePEMCertificate(contents []byte) (*x509.Certificate, error) {
+	var block *pem.Block
+	for {
+		block, contents = pem.Decode(contents)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// certSANs returns the DNS names, IP addresses and email addresses of the certificate
+func certSANs(cert *x509.Certificate) []string {
+	sans := slices.Clone(cert.DNSNames)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	return sans
+}
+
+// certSANIP returns the SAN as an IP string in canonical form if it's an IP address
+func certSANIP(name string) string {
+	if ip := net.ParseIP(name); ip != nil {
+		return ip.String()
+	}
+	return name
+}
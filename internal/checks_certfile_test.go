@@ -0,0 +1,138 @@
+package chkok
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCertFile writes a self-signed PEM certificate and its key valid for the duration
+func writeTestCertFile(t *testing.T, dir string, validFor time.Duration) (certPath, keyPath string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "chkok.example.com", Organization: []string{"chkok"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		DNSNames:     []string{"chkok.example.com", "www.chkok.example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err = os.WriteFile(certPath, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err = os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestCheckCertFile(t *testing.T) {
+	check := NewCheckCertFile("/no/such/path/exists")
+	want := "cert_file:/no/such/path/exists"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check cert file name, want %v got %v", want, got)
+	}
+	if check.Run().IsOK {
+		t.Error("invalid check cert file not exists, want not ok got ok")
+	}
+	check.absent = true
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("invalid check cert file absent, want ok got issues %v", result.Issues)
+	}
+
+	check = NewCheckCertFile("../LICENSE")
+	if result := check.Run(); result.IsOK {
+		t.Error("invalid check cert file not a certificate, want not ok got ok")
+	} else if len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), "no PEM certificate") {
+		t.Errorf("invalid check cert file not a certificate, want 1 issue got %v", result.Issues)
+	}
+}
+
+func TestCheckCertFileCertificate(t *testing.T) {
+	certPath, keyPath := writeTestCertFile(t, t.TempDir(), 30*24*time.Hour)
+	_, otherKeyPath := writeTestCertFile(t, t.TempDir(), 30*24*time.Hour)
+	expiredCertPath, _ := writeTestCertFile(t, t.TempDir(), -time.Minute)
+
+	result := NewCheckCertFile(certPath).Run()
+	metric := result.Metrics[len(result.Metrics)-1]
+	if metric.Name != "days_to_expiry" || metric.Value < 29.9 || metric.Value > 30 {
+		t.Errorf("check cert file want days to expiry metric of 30 days, got %v", result.Metrics)
+	}
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckCertFile)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Valid certificate", func(check *CheckCertFile) {}, true, ""},
+		{"Valid for long enough", func(check *CheckCertFile) {
+			check.MinValidFor = 14 * 24 * time.Hour
+		}, true, ""},
+		{"Expires too soon", func(check *CheckCertFile) {
+			check.MinValidFor = 60 * 24 * time.Hour
+		}, false, "expires in 29 days"},
+		{"Expired", func(check *CheckCertFile) {
+			check.path = expiredCertPath
+		}, false, "expired at"},
+		{"Subject and issuer match", func(check *CheckCertFile) {
+			check.Subject = "chkok.example.com"
+			check.Issuer = "CN=chkok.example.com,O=chkok"
+		}, true, ""},
+		{"Subject mismatch", func(check *CheckCertFile) {
+			check.Subject = "other.example.com"
+		}, false, "subject mismatch"},
+		{"SANs match", func(check *CheckCertFile) {
+			check.SANs = []string{"www.chkok.example.com", "127.0.0.1"}
+		}, true, ""},
+		{"SAN missing", func(check *CheckCertFile) {
+			check.SANs = []string{"chkok.example.com", "api.chkok.example.com"}
+		}, false, "missing subject alternative name \"api.chkok.example.com\""},
+		{"Key matches", func(check *CheckCertFile) {
+			check.KeyFile = keyPath
+		}, true, ""},
+		{"Key mismatch", func(check *CheckCertFile) {
+			check.KeyFile = otherKeyPath
+		}, false, "doesn't match the certificate"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckCertFile(certPath)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package chkok
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// byteSizeUnits are the multipliers of the byte size units, decimal (KB) and binary (KiB)
+var byteSizeUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseByteSize parses a number of bytes with an optional unit, like "512", "100MB" or "5GiB".
+// Single letter units (K, M, G, T) are binary.
+func ParseByteSize(size string) (uint64, error) {
+	size = strings.TrimSpace(size)
+	index := strings.IndexFunc(size, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if index == -1 {
+		index = len(size)
+	}
+	number, unit := size[:index], strings.ToLower(strings.TrimSpace(size[index:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q, unknown unit %q", size, unit)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q: %v", size, err)
+	}
+	return uint64(value * float64(multiplier)), nil
+}
+
+// CheckDisk checks for free space and inodes of the filesystem mounted on a path
+type CheckDisk struct {
+	baseCheck
+	Path                 string
+	MinFree              uint64  // min free bytes available to unprivileged users, 0 to skip
+	MinFreePercent       float64 // min percentage of free bytes, 0 to skip
+	MinFreeInodesPercent float64 // min percentage of free inodes, 0 to skip
+}
+
+// NewCheckDisk returns a new CheckDisk for the path without free space checks
+func NewCheckDisk(path string) *CheckDisk {
+	return &CheckDisk{Path: path}
+}
+
+// Name returns the unique name of the check
+func (chk *CheckDisk) Name() string {
+	return fmt.Sprintf("disk:%v", chk.Path)
+}
+
+// Run runs the check and returns the results
+func (chk *CheckDisk) Run() Result {
+	if chk.Path == "" {
+		panic("check disk path is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(chk.Path, &stat); err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("failed to stat filesystem: %v", err))
+		chk.status = StatusDone
+		return chk.result
+	}
+
+	blockSize := uint64(stat.Bsize) //nolint: gosec
+	free := statfsAvail(&stat) * blockSize
+	chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "free", Value: float64(free), Unit: "B"})
+	if chk.MinFree > 0 && free < chk.MinFree {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"disk free space %v bytes is less than min free %v bytes", free, chk.MinFree))
+	}
+	if stat.Blocks > 0 {
+		freePercent := float64(statfsAvail(&stat)) / float64(stat.Blocks) * 100
+		chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "free_percent", Value: freePercent, Unit: "%"})
+		if freePercent < chk.MinFreePercent {
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+				"disk free space %.2f%% is less than min free %v%%", freePercent, chk.MinFreePercent))
+		}
+	}
+	if stat.Files > 0 { // some filesystems (e.g. btrfs) have no fixed number of inodes
+		freeInodesPercent := float64(stat.Ffree) / float64(stat.Files) * 100
+		chk.result.Metrics = append(chk.result.Metrics,
+			Metric{Name: "free_inodes_percent", Value: freeInodesPercent, Unit: "%"})
+		if freeInodesPercent < chk.MinFreeInodesPercent {
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+				"disk free inodes %.2f%% is less than min free inodes %v%%", freeInodesPercent, chk.MinFreeInodesPercent))
+		}
+	}
+	chk.status = StatusDone
+	return chk.result
+}
@@ -0,0 +1,82 @@
+package chkok
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckDisk(t *testing.T) {
+	check := NewCheckDisk("/no/such/path/exists")
+	want := "disk:/no/such/path/exists"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check disk name, want %v got %v", want, got)
+	}
+	if result := check.Run(); result.IsOK {
+		t.Error("invalid check disk path not exists, want not ok got ok")
+	}
+}
+
+func TestCheckDiskFree(t *testing.T) {
+	path := t.TempDir()
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckDisk)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"No thresholds", func(check *CheckDisk) {}, true, ""},
+		{"Min free satisfied", func(check *CheckDisk) {
+			check.MinFree = 1
+		}, true, ""},
+		{"Min free not satisfied", func(check *CheckDisk) {
+			check.MinFree = 1 << 62
+		}, false, "is less than min free"},
+		{"Min free percent satisfied", func(check *CheckDisk) {
+			check.MinFreePercent = 0.0001
+		}, true, ""},
+		{"Min free percent not satisfied", func(check *CheckDisk) {
+			check.MinFreePercent = 100.1
+		}, false, "is less than min free 100.1%"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckDisk(path)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+			if len(result.Metrics) == 0 || result.Metrics[0].Name != "free" {
+				t.Errorf("Expected free metric, got %+v", result.Metrics)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	testCases := map[string]uint64{
+		"512":     512,
+		"512B":    512,
+		"100KB":   100000,
+		"1.5 KiB": 1536,
+		"5GiB":    5 << 30,
+		"5G":      5 << 30,
+		"2tb":     2000000000000,
+	}
+	for size, want := range testCases {
+		if got, err := ParseByteSize(size); err != nil || got != want {
+			t.Errorf("parse byte size %q want %v, got %v err %v", size, want, got, err)
+		}
+	}
+	for _, size := range []string{"", "GiB", "5PB", "1.2.3MB"} {
+		if _, err := ParseByteSize(size); err == nil {
+			t.Errorf("parse byte size %q want err, got nil", size)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package chkok
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSRecordTypes are the record types supported by CheckDNS
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "SRV"}
+
+// CheckDNS checks for the resolution of a name, the expected answers and the number of records
+type CheckDNS struct {
+	baseCheck
+	Host       string   // name to resolve
+	RecordType string   // one of DNSRecordTypes
+	Server     string   // address (host:port) of the DNS server, empty to use the system resolver
+	Answers    []string // expected answers, IPs, TXT values, host names of CNAME and MX or target:port of SRV
+	MinCount   int      // min number of records
+	Absent     bool     // if the name should not resolve (NXDOMAIN)
+	timeout    time.Duration
+}
+
+// NewCheckDNS returns a new CheckDNS for at least one A record of the host using the system resolver
+func NewCheckDNS(host string) *CheckDNS {
+	chk := CheckDNS{Host: host, RecordType: "A", MinCount: 1}
+	chk.SetTimeout(5 * time.Second)
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckDNS) Name() string {
+	name := fmt.Sprintf("dns:%v:%v", chk.RecordType, chk.Host)
+	if chk.Server != "" {
+		name += "@" + chk.Server
+	}
+	return name
+}
+
+// GetTimeout returns the timeout of the check
+func (chk *CheckDNS) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the timeout of the check
+func (chk *CheckDNS) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
+// Run runs the check and returns the results
+func (chk *CheckDNS) Run() Result {
+	if chk.Host == "" {
+		panic("check dns host is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	ctx := context.Background()
+	if chk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, chk.timeout)
+		defer cancel()
+	}
+	answers, err := chk.lookup(ctx)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if chk.Absent && errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			chk.status = StatusDone
+			return chk.result
+		}
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"failed to resolve %v %v: %w", chk.RecordType, chk.Host, err))
+		chk.status = StatusDone
+		if isTimeoutError(err) {
+			chk.status = StatusStopped
+		}
+		return chk.result
+	}
+
+	chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "count", Value: float64(len(answers))})
+	if chk.Absent {
+		if len(answers) > 0 { // no records of the type (NODATA) is absent too
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf("%v %v resolved to %v but should not exist",
+				chk.RecordType, chk.Host, strings.Join(answers, ", ")))
+		}
+		chk.status = StatusDone
+		return chk.result
+	}
+	if len(answers) < chk.MinCount {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"too few %v records, found %v but minimum is %v", chk.RecordType, len(answers), chk.MinCount))
+	}
+	for _, answer := range chk.Answers {
+		if !slices.Contains(answers, chk.normalizeAnswer(answer)) {
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf("%v %v answers %v are missing %v",
+				chk.RecordType, chk.Host, strings.Join(answers, ", "), answer))
+		}
+	}
+	chk.status = StatusDone
+	return chk.result
+}
+
+// lookup resolves the host and returns the normalized answers of the record type
+func (chk *CheckDNS) lookup(ctx context.Context) ([]string, error) {
+	answers, err := chk.lookupRecords(ctx, chk.resolver())
+	var dnsErr *net.DNSError
+	if chk.Server != "" && errors.As(err, &dnsErr) {
+		dnsErr.Server = chk.Server // instead of the system resolver server, which is not queried
+	}
+	if err != nil {
+		return nil, err
+	}
+	for index := range answers {
+		answers[index] = chk.normalizeAnswer(answers[index])
+	}
+	return answers, nil
+}
+
+// resolver returns the resolver querying the server, or the system resolver if no server is set.
+// Like the system resolver, the resolver querying the server looks up the names in the hosts file first.
+func (chk *CheckDNS) resolver() *net.Resolver {
+	if chk.Server == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, chk.Server)
+	}}
+}
+
+// lookupRecords resolves the host using the resolver and returns the answers of the record type
+func (chk *CheckDNS) lookupRecords(ctx context.Context, resolver *net.Resolver) ([]string, error) {
+	var answers []string
+	switch chk.RecordType {
+	case "A":
+		return lookupIPs(ctx, resolver, "ip4", chk.Host)
+	case "AAAA":
+		return lookupIPs(ctx, resolver, "ip6", chk.Host)
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, chk.Host)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(strings.TrimSuffix(cname, "."), strings.TrimSuffix(chk.Host, ".")) {
+			answers = append(answers, cname) // the host itself is returned when there's no CNAME record
+		}
+	case "MX":
+		records, err := resolver.LookupMX(ctx, chk.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			answers = append(answers, record.Host)
+		}
+	case "TXT":
+		return resolver.LookupTXT(ctx, chk.Host)
+	case "SRV":
+		_, records, err := resolver.LookupSRV(ctx, "", "", chk.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			answers = append(answers, net.JoinHostPort(record.Target, strconv.Itoa(int(record.Port))))
+		}
+	default:
+		return nil, fmt.Errorf("dns record type %v is not supported", chk.RecordType)
+	}
+	return answers, nil
+}
+
+// lookupIPs resolves the host using the resolver and returns the IPs of the network (ip4 or ip6)
+func lookupIPs(ctx context.Context, resolver *net.Resolver, network, host string) ([]string, error) {
+	ips, err := resolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	answers := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		answers = append(answers, ip.String())
+	}
+	return answers, nil
+}
+
+// normalizeAnswer returns the answer in canonical form to compare, TXT values are kept as is,
+// IPs are formatted and host names are lower cased without the trailing dot
+func (chk *CheckDNS) normalizeAnswer(answer string) string {
+	if chk.RecordType == "TXT" {
+		return answer
+	}
+	if ip := net.ParseIP(answer); ip != nil {
+		return ip.String()
+	}
+	answer = strings.ToLower(answer)
+	if host, port, err := net.SplitHostPort(answer); err == nil { // SRV target:port
+		return net.JoinHostPort(strings.TrimSuffix(host, "."), port)
+	}
+	return strings.TrimSuffix(answer, ".")
+}
@@ -0,0 +1,223 @@
+package chkok
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DNS record types of the test DNS server
+const (
+	testDNSTypeA     = 1
+	testDNSTypeCNAME = 5
+	testDNSTypeMX    = 15
+	testDNSTypeTXT   = 16
+	testDNSTypeSRV   = 33
+)
+
+// testDNSHeaderSize is the size of the DNS message header, followed by the question
+const testDNSHeaderSize = 12
+
+// testDNSTypeClassSize is the size of the type and class of the DNS question after the name
+const testDNSTypeClassSize = 4
+
+// encodeTestDNSName encodes the domain name as length prefixed labels
+func encodeTestDNSName(name string) []byte {
+	var encoded []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		encoded = append(encoded, byte(len(label))) //nolint: gosec
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+// testDNSResponse returns the response to the DNS query with the answers of the records, which are
+// the rdata of each record type by name. Names without records get an NXDOMAIN response.
+func testDNSResponse(query []byte, records map[string]map[uint16][][]byte) []byte {
+	if len(query) < testDNSHeaderSize {
+		return nil
+	}
+	offset := testDNSHeaderSize // question name is after the header
+	var labels []string
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	offset++ // root label, followed by the type and class
+	if offset+testDNSTypeClassSize > len(query) {
+		return nil
+	}
+	question := query[testDNSHeaderSize : offset+testDNSTypeClassSize]
+	qtype := binary.BigEndian.Uint16(query[offset:])
+	types, found := records[strings.ToLower(strings.Join(labels, "."))]
+	flags := uint16(0x8180) // response, recursion desired and available
+	if !found {
+		flags |= 3 // NXDOMAIN
+	}
+	answers := types[qtype]
+	response := binary.BigEndian.AppendUint16(nil, binary.BigEndian.Uint16(query)) // id
+	response = binary.BigEndian.AppendUint16(response, flags)
+	response = binary.BigEndian.AppendUint16(response, 1)
+	response = binary.BigEndian.AppendUint16(response, uint16(len(answers))) //nolint: gosec
+	// no authority and additional records
+	response = binary.BigEndian.AppendUint32(response, 0)
+	response = append(response, question...)
+	for _, rdata := range answers {
+		response = append(response, 0xc0, testDNSHeaderSize) // pointer to the question name
+		response = binary.BigEndian.AppendUint16(response, qtype)
+		response = binary.BigEndian.AppendUint16(response, 1) // class IN
+		response = binary.BigEndian.AppendUint32(response, 60)
+		response = binary.BigEndian.AppendUint16(response, uint16(len(rdata))) //nolint: gosec
+		response = append(response, rdata...)
+	}
+	return response
+}
+
+// serveTestDNS serves the records on a local udp port, and returns the address of the server
+func serveTestDNS(t *testing.T, records map[string]map[uint16][][]byte) string {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on udp: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response := testDNSResponse(buf[:n], records); response != nil {
+				_, _ = conn.WriteTo(response, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestCheckDNS(t *testing.T) {
+	check := NewCheckDNS("app.test")
+	want := "dns:A:app.test"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check dns name, want %v got %v", want, got)
+	}
+	check.Server = "127.0.0.1:53"
+	want = "dns:A:app.test@127.0.0.1:53"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check dns name, want %v got %v", want, got)
+	}
+
+	check = NewCheckDNS("localhost")
+	check.Answers = []string{"127.0.0.1"}
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("check dns of localhost using the system resolver, want ok got issues %v", result.Issues)
+	}
+	check.RecordType, check.Answers = "CNAME", nil
+	if result := check.Run(); result.IsOK {
+		t.Errorf("check dns of localhost CNAME using the system resolver, want not ok got ok")
+	}
+}
+
+func TestCheckDNSServer(t *testing.T) {
+	mxRecord := append([]byte{0, 10}, encodeTestDNSName("Mail.App.Test.")...)
+	srvRecord := append([]byte{0, 10, 0, 5, 0x01, 0x85}, encodeTestDNSName("ldap.app.test")...) // port 389
+	address := serveTestDNS(t, map[string]map[uint16][][]byte{
+		"app.test": {
+			testDNSTypeA:   {{10, 0, 0, 1}, {10, 0, 0, 2}},
+			testDNSTypeMX:  {mxRecord},
+			testDNSTypeTXT: {append([]byte{byte(len("v=spf1 -all"))}, "v=spf1 -all"...)},
+		},
+		"_ldap._tcp.app.test": {testDNSTypeSRV: {srvRecord}},
+		"www.app.test":        {testDNSTypeCNAME: {encodeTestDNSName("app.test")}},
+	})
+	silentAddress := serveTestUDPEcho(t, false)
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckDNS)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"A records", func(check *CheckDNS) {
+			check.Answers = []string{"10.0.0.2", "10.0.0.1"}
+			check.MinCount = 2
+		}, true, ""},
+		{"A answer missing", func(check *CheckDNS) {
+			check.Answers = []string{"10.0.0.3"}
+		}, false, "A app.test answers 10.0.0.1, 10.0.0.2 are missing 10.0.0.3"},
+		{"Too few records", func(check *CheckDNS) {
+			check.MinCount = 3
+		}, false, "too few A records, found 2 but minimum is 3"},
+		{"MX record", func(check *CheckDNS) {
+			check.RecordType = "MX"
+			check.Answers = []string{"mail.app.test."}
+		}, true, ""},
+		{"TXT record", func(check *CheckDNS) {
+			check.RecordType = "TXT"
+			check.Answers = []string{"v=spf1 -all"}
+		}, true, ""},
+		{"SRV record", func(check *CheckDNS) {
+			check.Host = "_ldap._tcp.app.test"
+			check.RecordType = "SRV"
+			check.Answers = []string{"ldap.app.test:389"}
+		}, true, ""},
+		{"CNAME record", func(check *CheckDNS) {
+			check.Host = "www.app.test"
+			check.RecordType = "CNAME"
+			check.Answers = []string{"app.test"}
+		}, true, ""},
+		{"No CNAME record", func(check *CheckDNS) {
+			check.RecordType = "CNAME"
+		}, false, "too few CNAME records, found 0 but minimum is 1"},
+		{"Hosts file is used", func(check *CheckDNS) {
+			check.Host = "localhost"
+			check.Answers = []string{"127.0.0.1"}
+		}, true, ""},
+		{"No such host", func(check *CheckDNS) {
+			check.Host = "missing.app.test"
+		}, false, "failed to resolve A missing.app.test: lookup missing.app.test on 127.0.0.1"},
+		{"No such host is absent", func(check *CheckDNS) {
+			check.Host = "missing.app.test"
+			check.Absent = true
+		}, true, ""},
+		{"Resolves but should be absent", func(check *CheckDNS) {
+			check.Absent = true
+		}, false, "A app.test resolved to 10.0.0.1, 10.0.0.2 but should not exist"},
+		{"No records of the type are absent", func(check *CheckDNS) {
+			check.RecordType = "SRV"
+			check.Absent = true
+		}, true, ""},
+		{"No CNAME record is absent", func(check *CheckDNS) {
+			check.RecordType = "CNAME"
+			check.Absent = true
+		}, true, ""},
+		{"Server not responding", func(check *CheckDNS) {
+			check.Server = silentAddress
+			check.Absent = true
+		}, false, "failed to resolve A app.test"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckDNS("app.test")
+			check.Server = address
+			check.SetTimeout(500 * time.Millisecond)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
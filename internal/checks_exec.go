@@ -0,0 +1,139 @@
+package chkok
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxExecOutputReport is the max number of bytes of the stderr included in the issues
+const maxExecOutputReport = 256
+
+// limitedBuffer is a buffer keeping up to the limit of the written bytes, and discarding the rest
+type limitedBuffer struct {
+	buffer bytes.Buffer
+	limit  int
+}
+
+// Write writes the bytes up to the limit to the buffer, always reporting all bytes written
+func (buf *limitedBuffer) Write(data []byte) (int, error) {
+	if remaining := buf.limit - buf.buffer.Len(); remaining > 0 {
+		buf.buffer.Write(truncateBytes(data, remaining))
+	}
+	return len(data), nil
+}
+
+// Bytes returns the bytes kept in the buffer
+func (buf *limitedBuffer) Bytes() []byte {
+	return buf.buffer.Bytes()
+}
+
+// CheckExec checks for the exit code and the output of a command. The command runs without a shell,
+// in its own process group which is killed on timeout.
+type CheckExec struct {
+	baseCheck
+	Command        []string       // the executable and its arguments
+	Env            []string       // environment variables (KEY=value) added to the environment of the command
+	Dir            string         // working directory of the command, empty to use the current directory
+	ExitCode       int            // expected exit code
+	StdoutContains string         // expected to be in the stdout, empty to skip
+	StdoutRegex    *regexp.Regexp // expected to match the stdout, nil to skip
+	StderrContains string         // expected to be in the stderr, empty to skip
+	StderrRegex    *regexp.Regexp // expected to match the stderr, nil to skip
+	MaxReadSize    int            // max number of bytes of the stdout and stderr kept to check
+	timeout        time.Duration
+}
+
+// NewCheckExec returns a new CheckExec for the command to exit successfully
+func NewCheckExec(command ...string) *CheckExec {
+	chk := CheckExec{Command: command, MaxReadSize: DefaultMaxReadSize}
+	chk.SetTimeout(5 * time.Second)
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckExec) Name() string {
+	return fmt.Sprintf("exec:%v", strings.Join(chk.Command, " "))
+}
+
+// GetTimeout returns the timeout of the check
+func (chk *CheckExec) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the timeout of the check
+func (chk *CheckExec) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
+// Run runs the check and returns the results
+func (chk *CheckExec) Run() Result {
+	if len(chk.Command) == 0 || chk.Command[0] == "" {
+		panic("check exec command is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	ctx := context.Background()
+	if chk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, chk.timeout)
+		defer cancel()
+	}
+	stdout := &limitedBuffer{limit: chk.MaxReadSize}
+	stderr := &limitedBuffer{limit: chk.MaxReadSize}
+	cmd := exec.CommandContext(ctx, chk.Command[0], chk.Command[1:]...) //nolint: gosec
+	cmd.Dir = chk.Dir
+	if len(chk.Env) > 0 {
+		cmd.Env = append(os.Environ(), chk.Env...)
+	}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { // kill the child processes too
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second // don't wait on output of child processes out of the group after the timeout
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("command timed out after %v: %w", chk.timeout, ctx.Err()))
+		chk.status = StatusStopped
+		return chk.result
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("failed to run command: %w", err))
+		chk.status = StatusDone
+		return chk.result
+	}
+	if exitCode := cmd.ProcessState.ExitCode(); exitCode != chk.ExitCode {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("exit code mismatch want %v got %v, stderr: %q",
+			chk.ExitCode, exitCode, truncateBytes(bytes.TrimSpace(stderr.Bytes()), maxExecOutputReport)))
+	}
+	checkExecOutput("stdout", stdout.Bytes(), chk.StdoutContains, chk.StdoutRegex, &chk.result)
+	checkExecOutput("stderr", stderr.Bytes(), chk.StderrContains, chk.StderrRegex, &chk.result)
+	chk.status = StatusDone
+	return chk.result
+}
+
+// checkExecOutput checks the output of the command contains the string and matches the regex,
+// and updates the provided result
+func checkExecOutput(stream string, output []byte, contains string, regex *regexp.Regexp, result *Result) {
+	if contains != "" && !bytes.Contains(output, []byte(contains)) {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("%v does not contain %q", stream, contains))
+	}
+	if regex != nil && !regex.Match(output) {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("%v does not match regex %q", stream, regex))
+	}
+}
@@ -0,0 +1,94 @@
+package chkok
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckExec(t *testing.T) {
+	check := NewCheckExec("/usr/local/bin/check-queue", "--max", "10")
+	want := "exec:/usr/local/bin/check-queue --max 10"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check exec name, want %v got %v", want, got)
+	}
+
+	check = NewCheckExec("sh", "-c", "sleep 5 & sleep 5") // the child process keeps the output open
+	check.SetTimeout(200 * time.Millisecond)
+	start := time.Now()
+	if result := check.Run(); result.IsOK || check.Status() != StatusStopped {
+		t.Errorf("check exec timeout, want not ok and stopped, got %v %v", result.IsOK, check.Status())
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("check exec timeout, want the process group killed on timeout, took %v", elapsed)
+	}
+}
+
+func TestCheckExecResults(t *testing.T) {
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckExec)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Exit successfully", func(check *CheckExec) {}, true, ""},
+		{"Exit code mismatch", func(check *CheckExec) {
+			check.Command = []string{"sh", "-c", "echo 'queue is full' >&2; exit 2"}
+		}, false, `exit code mismatch want 0 got 2, stderr: "queue is full"`},
+		{"Expected exit code", func(check *CheckExec) {
+			check.Command = []string{"sh", "-c", "exit 3"}
+			check.ExitCode = 3
+		}, true, ""},
+		{"Stdout contains and matches", func(check *CheckExec) {
+			check.Command = []string{"echo", "12 jobs queued"}
+			check.StdoutContains = "jobs"
+			check.StdoutRegex = regexp.MustCompile(`^\d+ jobs`)
+		}, true, ""},
+		{"Stdout does not contain", func(check *CheckExec) {
+			check.Command = []string{"echo", "12 jobs queued"}
+			check.StdoutContains = "0 jobs"
+		}, false, `stdout does not contain "0 jobs"`},
+		{"Stderr does not match", func(check *CheckExec) {
+			check.Command = []string{"sh", "-c", "echo warning >&2"}
+			check.StderrRegex = regexp.MustCompile("^$")
+		}, false, `stderr does not match regex "^$"`},
+		{"Stderr contains", func(check *CheckExec) {
+			check.Command = []string{"sh", "-c", "echo deprecated >&2"}
+			check.StderrContains = "deprecated"
+		}, true, ""},
+		{"Environment and working directory", func(check *CheckExec) {
+			check.Command = []string{"sh", "-c", `echo "$QUEUE in $(pwd)"`}
+			check.Env = []string{"QUEUE=jobs"}
+			check.Dir = "/"
+			check.StdoutContains = "jobs in /\n"
+		}, true, ""},
+		{"Output over max read size", func(check *CheckExec) {
+			check.Command = []string{"echo", "12 jobs queued"}
+			check.MaxReadSize = 2
+			check.StdoutContains = "jobs"
+		}, false, `stdout does not contain "jobs"`},
+		{"Command not found", func(check *CheckExec) {
+			check.Command = []string{"/no/such/command"}
+		}, false, "failed to run command"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckExec("true")
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+			if check.Status() != StatusDone {
+				t.Errorf("Expected status done, got %v", check.Status())
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package chkok
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHTTPBodyRead is the max number of response body bytes read to match the expected body
+const maxHTTPBodyRead = 1024 * 1024
+
+// StatusCodeRange is an inclusive range of HTTP status codes
+type StatusCodeRange struct {
+	Min int
+	Max int
+}
+
+// Contains returns true if the status code is in the range
+func (r StatusCodeRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+// String returns the range as "200-299", or a single code if the range has only one
+func (r StatusCodeRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParseStatusCodeRange parses a status code "200", a range "200-299" or a class "2xx"
+func ParseStatusCodeRange(value string) (StatusCodeRange, error) {
+	var codeRange StatusCodeRange
+	var err error
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) == 3 && strings.HasSuffix(value, "xx") {
+		var class int
+		if class, err = strconv.Atoi(value[:1]); err != nil || class < 1 || class > 5 {
+			return codeRange, fmt.Errorf("invalid status code class '%v'", value)
+		}
+		return StatusCodeRange{Min: class * 100, Max: class*100 + 99}, nil
+	}
+	low, high, isRange := strings.Cut(value, "-")
+	if codeRange.Min, err = strconv.Atoi(strings.TrimSpace(low)); err != nil {
+		return codeRange, fmt.Errorf("invalid status code '%v'", value)
+	}
+	codeRange.Max = codeRange.Min
+	if isRange {
+		if codeRange.Max, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return codeRange, fmt.Errorf("invalid status code range '%v'", value)
+		}
+	}
+	if codeRange.Min > codeRange.Max {
+		return codeRange, fmt.Errorf("invalid status code range '%v'", value)
+	}
+	return codeRange, nil
+}
+
+// CheckHTTP checks for an HTTP(S) endpoint by sending a request and validating the response
+type CheckHTTP struct {
+	baseCheck
+	URL          string
+	Method       string
+	StatusCodes  []StatusCodeRange // defaults to 2xx if empty
+	Headers      map[string]string // expected response headers, empty value to only check existence
+	BodyContains string
+	BodyRegex    *regexp.Regexp
+	MaxLatency   time.Duration // 0 to skip
+	timeout      time.Duration
+}
+
+// NewCheckHTTP returns a CheckHTTP for local http availability by default
+func NewCheckHTTP() *CheckHTTP {
+	chk := CheckHTTP{
+		URL:         "http://127.0.0.1:80/",
+		Method:      http.MethodGet,
+		StatusCodes: []StatusCodeRange{{Min: 200, Max: 299}},
+		Headers:     map[string]string{},
+	}
+	chk.SetTimeout(5 * time.Second)
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckHTTP) Name() string {
+	return fmt.Sprintf("http:%v %v", chk.Method, chk.URL)
+}
+
+// GetTimeout gets the max duration for the check to timeout
+func (chk *CheckHTTP) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the max duration for the check to timeout
+func (chk *CheckHTTP) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
+// Run runs the check and returns the results
+func (chk *CheckHTTP) Run() Result {
+	if chk.URL == "" {
+		panic("check http url is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	ctx := context.Background()
+	if chk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, chk.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, chk.Method, chk.URL, http.NoBody)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		return chk.result
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		if isTimeoutError(err) {
+			chk.status = StatusStopped
+		}
+		return chk.result
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodyRead))
+	elapsed := time.Since(start)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("failed to read response body: %v", err))
+		chk.status = StatusDone
+		if isTimeoutError(err) {
+			chk.status = StatusStopped
+		}
+		return chk.result
+	}
+
+	chk.checkResponse(resp, body, &chk.result)
+	if chk.MaxLatency > 0 && elapsed > chk.MaxLatency {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"response too slow, took %v but max latency is %v", elapsed, chk.MaxLatency))
+	}
+	chk.status = StatusDone
+	return chk.result
+}
+
+// checkResponse checks for the response status code, headers and body and updates the provided result
+func (chk *CheckHTTP) checkResponse(resp *http.Response, body []byte, result *Result) {
+	statusOK := len(chk.StatusCodes) == 0 && resp.StatusCode >= 200 && resp.StatusCode <= 299
+	for _, codeRange := range chk.StatusCodes {
+		if codeRange.Contains(resp.StatusCode) {
+			statusOK = true
+			break
+		}
+	}
+	if !statusOK {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"status code mismatch want %v got %v", chk.StatusCodes, resp.StatusCode))
+	}
+
+	for header, value := range chk.Headers {
+		got, ok := resp.Header[http.CanonicalHeaderKey(header)]
+		if !ok {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf("response is missing header %v", header))
+		} else if value != "" && got[0] != value {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"header %v mismatch want %q got %q", header, value, got[0]))
+		}
+	}
+
+	if chk.BodyContains != "" && !strings.Contains(string(body), chk.BodyContains) {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("response body doesn't contain %q", chk.BodyContains))
+	}
+	if chk.BodyRegex != nil && !chk.BodyRegex.Match(body) {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"response body doesn't match regex %q", chk.BodyRegex.String()))
+	}
+}
+
+// isTimeoutError returns true if the error is caused by a timeout
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
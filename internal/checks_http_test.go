@@ -0,0 +1,148 @@
+package chkok
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHTTPServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Health", "green")
+		fmt.Fprint(w, `{"status": "healthy", "version": "1.2.3"}`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		fmt.Fprint(w, "slow")
+	})
+	mux.HandleFunc("/teapot", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCheckHTTP(t *testing.T) {
+	var check *CheckHTTP
+	var got, want string
+	check = NewCheckHTTP()
+	if got = check.Suite(); got != want {
+		t.Errorf("invalid check http suite, want empty got %v", got)
+	}
+	want = "http:GET http://127.0.0.1:80/"
+	if got = check.Name(); got != want {
+		t.Errorf("invalid check http name, want %v got %v", want, got)
+	}
+	if gotStatus := check.Status(); gotStatus != StatusUnknown {
+		t.Errorf("invalid check http status, want %v got %v", StatusUnknown, gotStatus)
+	}
+}
+
+func TestCheckHTTPResponse(t *testing.T) {
+	server := newTestHTTPServer()
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckHTTP)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Status OK", func(check *CheckHTTP) {}, true, ""},
+		{"Status not found", func(check *CheckHTTP) {
+			check.URL = server.URL + "/not-found"
+		}, false, "status code mismatch"},
+		{"Status in range", func(check *CheckHTTP) {
+			check.URL = server.URL + "/teapot"
+			check.StatusCodes = []StatusCodeRange{{Min: 200, Max: 299}, {Min: 400, Max: 418}}
+		}, true, ""},
+		{"Header exists", func(check *CheckHTTP) {
+			check.Headers = map[string]string{"x-health": ""}
+		}, true, ""},
+		{"Header value mismatch", func(check *CheckHTTP) {
+			check.Headers = map[string]string{"X-Health": "red"}
+		}, false, "header X-Health mismatch"},
+		{"Header missing", func(check *CheckHTTP) {
+			check.Headers = map[string]string{"X-Missing": ""}
+		}, false, "missing header"},
+		{"Body contains", func(check *CheckHTTP) {
+			check.BodyContains = `"status": "healthy"`
+		}, true, ""},
+		{"Body doesn't contain", func(check *CheckHTTP) {
+			check.BodyContains = "unhealthy"
+		}, false, "doesn't contain"},
+		{"Body matches regex", func(check *CheckHTTP) {
+			check.BodyRegex = regexp.MustCompile(`"version": "1\.\d+\.\d+"`)
+		}, true, ""},
+		{"Body doesn't match regex", func(check *CheckHTTP) {
+			check.BodyRegex = regexp.MustCompile(`"version": "2\.`)
+		}, false, "doesn't match regex"},
+		{"Latency exceeded", func(check *CheckHTTP) {
+			check.URL = server.URL + "/slow"
+			check.MaxLatency = 100 * time.Millisecond
+		}, false, "response too slow"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckHTTP()
+			check.URL = server.URL + "/health"
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+			if check.Status() != StatusDone {
+				t.Errorf("invalid check http status, want %v got %v", StatusDone, check.Status())
+			}
+		})
+	}
+}
+
+func TestCheckHTTPTimeout(t *testing.T) {
+	server := newTestHTTPServer()
+	defer server.Close()
+
+	check := NewCheckHTTP()
+	check.URL = server.URL + "/slow"
+	check.SetTimeout(50 * time.Millisecond)
+	if got := check.Run(); got.IsOK {
+		t.Errorf("invalid check http timeout, want not ok got ok")
+	}
+	if check.Status() != StatusStopped {
+		t.Errorf("invalid check http status, want %v got %v", StatusStopped, check.Status())
+	}
+}
+
+func TestParseStatusCodeRange(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    StatusCodeRange
+		wantErr bool
+	}{
+		{"200", StatusCodeRange{200, 200}, false},
+		{"200-204", StatusCodeRange{200, 204}, false},
+		{"3xx", StatusCodeRange{300, 399}, false},
+		{"9xx", StatusCodeRange{}, true},
+		{"204-200", StatusCodeRange{}, true},
+		{"ok", StatusCodeRange{}, true},
+	}
+	for _, tc := range testCases {
+		got, err := ParseStatusCodeRange(tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parse status code range %q want err %v got %v", tc.value, tc.wantErr, err)
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Errorf("parse status code range %q want %v got %v", tc.value, tc.want, got)
+		}
+	}
+}
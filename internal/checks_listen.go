@@ -0,0 +1,299 @@
+package chkok
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procNetTCPListen is the state of listening sockets in /proc/net/tcp and tcp6
+const procNetTCPListen = "0A"
+
+// procNetUDPUnconnected is the state of bound and unconnected (receiving) sockets in /proc/net/udp and udp6
+const procNetUDPUnconnected = "07"
+
+// procNetUnixAcceptCon is the flag of listening sockets in /proc/net/unix
+const procNetUnixAcceptCon = 0x10000
+
+// procNetUnixDgram is the type of datagram sockets in /proc/net/unix
+const procNetUnixDgram = "0002"
+
+// procSocket is a listening socket read from the proc filesystem
+type procSocket struct {
+	ip    net.IP // bind address of tcp and udp sockets
+	port  int
+	path  string // path of unix sockets, abstract sockets start with @
+	uid   int    // owner of tcp and udp sockets, -1 for unix sockets
+	inode string
+}
+
+// CheckListen checks for a listening tcp or udp port or unix socket from the proc filesystem,
+// without connecting to it, and its bind address and owner
+type CheckListen struct {
+	baseCheck
+	Network     string // tcp, udp or unix
+	Port        int    // port of tcp and udp sockets
+	Path        string // path of unix sockets
+	Address     net.IP // expected bind address of tcp and udp sockets, nil to skip
+	NoWildcard  bool   // fail if tcp or udp sockets are bound to any address (0.0.0.0 or ::)
+	ProcessName string // expected base name of the executable or short name of the owner process, empty to skip
+	Absent      bool   // if nothing should listen
+	uid         int    // expected owner of the sockets, -1 to skip
+	procRoot    string
+}
+
+// NewCheckListen returns a new CheckListen for a listening tcp port
+func NewCheckListen(port int) *CheckListen {
+	return &CheckListen{Network: "tcp", Port: port, uid: -1, procRoot: "/proc"}
+}
+
+// Name returns the unique name of the check
+func (chk *CheckListen) Name() string {
+	if chk.Network == "unix" {
+		return fmt.Sprintf("listen:unix:%v", chk.Path)
+	}
+	return fmt.Sprintf("listen:%v:%v", chk.Network, chk.Port)
+}
+
+// Run runs the check and returns the results
+func (chk *CheckListen) Run() Result {
+	if chk.Network == "unix" && chk.Path == "" {
+		panic("check listen unix socket path is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	sockets, err := chk.listeningSockets()
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		return chk.result
+	}
+	target := chk.Name()[len("listen:"):]
+	if chk.Absent {
+		if len(sockets) > 0 {
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf("%v is listening but should not", target))
+		}
+		chk.status = StatusDone
+		return chk.result
+	}
+	if len(sockets) == 0 {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("nothing is listening on %v", target))
+		chk.status = StatusDone
+		return chk.result
+	}
+	chk.checkAddresses(sockets, target, &chk.result)
+	chk.checkOwners(sockets, target, &chk.result)
+	chk.status = StatusDone
+	return chk.result
+}
+
+// checkAddresses checks the bind addresses of the sockets and updates the provided result
+func (chk *CheckListen) checkAddresses(sockets []procSocket, target string, result *Result) {
+	if chk.Network == "unix" {
+		return
+	}
+	var addresses []string
+	bound := false
+	for _, socket := range sockets {
+		addresses = append(addresses, socket.ip.String())
+		if chk.NoWildcard && socket.ip.IsUnspecified() {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf("%v is listening on any address %v", target, socket.ip))
+		}
+		bound = bound || socket.ip.Equal(chk.Address)
+	}
+	if chk.Address != nil && !bound {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("%v is not listening on %v but on %v",
+			target, chk.Address, strings.Join(addresses, ", ")))
+	}
+}
+
+// checkOwners checks the owner user and process of the sockets and updates the provided result
+func (chk *CheckListen) checkOwners(sockets []procSocket, target string, result *Result) {
+	var owners map[string][]procProcess
+	if chk.ProcessName != "" || (chk.uid > -1 && chk.Network == "unix") {
+		inodes := map[string]bool{}
+		for _, socket := range sockets {
+			inodes[socket.inode] = true
+		}
+		owners = socketOwners(chk.procRoot, inodes)
+	}
+	for _, socket := range sockets {
+		if chk.uid > -1 && socket.uid > -1 && socket.uid != chk.uid {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"%v owner mismatch want uid %v got %v", target, chk.uid, socket.uid))
+		}
+		if owners != nil {
+			chk.checkOwnerProcesses(owners[socket.inode], socket, target, result)
+		}
+	}
+}
+
+// checkOwnerProcesses checks the processes owning the socket and updates the provided result
+func (chk *CheckListen) checkOwnerProcesses(processes []procProcess, socket procSocket, target string, result *Result) {
+	if len(processes) == 0 {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"%v owner process not found (inode %v), it may not be accessible", target, socket.inode))
+		return
+	}
+	for _, process := range processes {
+		if chk.uid > -1 && socket.uid == -1 && process.uid != chk.uid { // unix sockets are owned by the process
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"%v owner process %v mismatch want uid %v got %v", target, process.pid, chk.uid, process.uid))
+		}
+		dir := filepath.Join(chk.procRoot, strconv.Itoa(process.pid))
+		if chk.ProcessName != "" && process.name != chk.ProcessName && procExeName(dir) != chk.ProcessName {
+			result.IsOK = false
+			result.Issues = append(result.Issues, fmt.Errorf(
+				"%v owner process %v name mismatch want %v got %v", target, process.pid, chk.ProcessName, process.name))
+		}
+	}
+}
+
+// listeningSockets returns the listening sockets of the network matching the port or path
+func (chk *CheckListen) listeningSockets() ([]procSocket, error) {
+	files := map[string][]string{"tcp": {"tcp", "tcp6"}, "udp": {"udp", "udp6"}, "unix": {"unix"}}[chk.Network]
+	if files == nil {
+		return nil, fmt.Errorf("listen check network %v is not supported", chk.Network)
+	}
+	var matches []procSocket
+	for _, file := range files {
+		path := filepath.Join(chk.procRoot, "net", file)
+		var sockets []procSocket
+		var err error
+		if chk.Network == "unix" {
+			sockets, err = readProcNetUnix(path)
+		} else {
+			sockets, err = readProcNetIP(path, chk.Network)
+		}
+		if errors.Is(err, os.ErrNotExist) && file != files[0] { // no IPv6 support
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, socket := range sockets {
+			if (chk.Network == "unix" && socket.path == chk.Path) || (chk.Network != "unix" && socket.port == chk.Port) {
+				matches = append(matches, socket)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// readProcNetIP returns the listening sockets in a tcp, tcp6, udp or udp6 file of /proc/net
+func readProcNetIP(path, network string) ([]procSocket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	state := procNetTCPListen
+	if network == "udp" {
+		state = procNetUDPUnconnected
+	}
+	var sockets []procSocket
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		hexIP, hexPort, found := strings.Cut(fields[1], ":")
+		port, portErr := strconv.ParseUint(hexPort, 16, 16)
+		ip, ipErr := parseProcNetIP(hexIP)
+		uid, uidErr := strconv.Atoi(fields[7])
+		if !found || portErr != nil || ipErr != nil || uidErr != nil {
+			return nil, fmt.Errorf("invalid socket %q in %v", fields[1], path)
+		}
+		sockets = append(sockets, procSocket{ip: ip, port: int(port), uid: uid, inode: fields[9]})
+	}
+	return sockets, scanner.Err()
+}
+
+// parseProcNetIP parses the hex encoded IP of /proc/net files, as 32 bit words in host byte order
+func parseProcNetIP(hexIP string) (net.IP, error) {
+	ip, err := hex.DecodeString(hexIP)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return nil, fmt.Errorf("invalid ip %q", hexIP)
+	}
+	for word := 0; word < len(ip); word += 4 { // little endian words, as on the supported architectures
+		ip[word], ip[word+1], ip[word+2], ip[word+3] = ip[word+3], ip[word+2], ip[word+1], ip[word]
+	}
+	return net.IP(ip), nil
+}
+
+// readProcNetUnix returns the listening (or receiving datagram) sockets with a path in /proc/net/unix
+func readProcNetUnix(path string) ([]procSocket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var sockets []procSocket
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket flags %q in %v", fields[3], path)
+		}
+		if flags&procNetUnixAcceptCon != 0 || fields[4] == procNetUnixDgram {
+			sockets = append(sockets, procSocket{path: fields[7], uid: -1, inode: fields[6]})
+		}
+	}
+	return sockets, scanner.Err()
+}
+
+// socketOwners returns the processes with open file descriptors of the socket inodes, by inode
+func socketOwners(procRoot string, inodes map[string]bool) map[string][]procProcess {
+	owners := map[string][]procProcess{}
+	pids, err := listProcPIDs(procRoot)
+	if err != nil {
+		return owners
+	}
+	for _, pid := range pids {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+		entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if err != nil { // process is gone, or not accessible
+			continue
+		}
+		owned := map[string]bool{} // file descriptors can be duplicates of the same socket
+		for _, entry := range entries {
+			link, err := os.Readlink(filepath.Join(dir, "fd", entry.Name()))
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			if err == nil && strings.HasPrefix(link, "socket:[") && inodes[inode] {
+				owned[inode] = true
+			}
+		}
+		process := procProcess{pid: pid, uid: -1}
+		if len(owned) == 0 || readProcStatus(filepath.Join(dir, "status"), &process) != nil {
+			continue
+		}
+		for inode := range owned {
+			owners[inode] = append(owners[inode], process)
+		}
+	}
+	return owners
+}
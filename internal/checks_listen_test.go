@@ -0,0 +1,171 @@
+package chkok
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testProcNetIPHeader is the header of the tcp and udp files in /proc/net
+const testProcNetIPHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  " +
+	"timeout inode"
+
+// writeTestProcNet writes the header and the socket lines to the file in the net dir of a fake proc filesystem
+func writeTestProcNet(t *testing.T, procRoot, file, header string, lines ...string) {
+	dir := filepath.Join(procRoot, "net")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("Failed to create proc net dir: %v", err)
+	}
+	content := header + "\n" + strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write proc net file: %v", err)
+	}
+}
+
+// writeTestProcSocketFD links the file descriptor of the process in a fake proc filesystem to the socket inode
+func writeTestProcSocketFD(t *testing.T, procRoot string, pid int, fd, inode string) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid), "fd")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("Failed to create proc fd dir: %v", err)
+	}
+	if err := os.Symlink("socket:["+inode+"]", filepath.Join(dir, fd)); err != nil {
+		t.Fatalf("Failed to link proc fd: %v", err)
+	}
+}
+
+func TestCheckListen(t *testing.T) {
+	check := NewCheckListen(8080)
+	want := "listen:tcp:8080"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check listen name, want %v got %v", want, got)
+	}
+	check.Network, check.Path = "unix", "/run/app.sock"
+	want = "listen:unix:/run/app.sock"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check listen name, want %v got %v", want, got)
+	}
+
+	if _, err := os.Stat("/proc/net/tcp"); err != nil {
+		t.Skipf("proc filesystem is not available: %v", err)
+	}
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on tcp: %v", err)
+	}
+	defer listener.Close()
+	check = NewCheckListen(listener.Addr().(*net.TCPAddr).Port)
+	check.Address = net.ParseIP("127.0.0.1")
+	check.NoWildcard = true
+	check.uid = os.Getuid()
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("check listen of the test listener, want ok got issues %v", result.Issues)
+	}
+}
+
+func TestCheckListenProcNet(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProcNet(t, procRoot, "tcp", testProcNetIPHeader,
+		"   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1001 1 0 100 0 0 10 0",
+		"   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0 100 0 0 10 0",
+		"   2: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0 100 0 0 10 0")
+	writeTestProcNet(t, procRoot, "tcp6", testProcNetIPHeader,
+		"   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A "+
+			"00000000:00000000 00:00000000 00000000     0        0 1004 1 0 100 0 0 10 0")
+	writeTestProcNet(t, procRoot, "udp", testProcNetIPHeader,
+		"  10: 0100007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 1005 2 0 0")
+	writeTestProcNet(t, procRoot, "unix", "Num       RefCount Protocol Flags    Type St Inode Path",
+		"0000000000000000: 00000002 00000000 00010000 0001 01  1006 /run/app.sock",
+		"0000000000000000: 00000003 00000000 00000000 0001 03  1007 /run/app.sock",
+		"0000000000000000: 00000002 00000000 00000000 0002 01  1008 /run/syslog.sock")
+	writeTestProcProcess(t, procRoot, 200, "app", "S", 1000, "/usr/bin/app")
+	writeTestProcSocketFD(t, procRoot, 200, "3", "1001")
+	writeTestProcSocketFD(t, procRoot, 200, "4", "1006")
+	writeTestProcSocketFD(t, procRoot, 200, "5", "1006")
+	writeTestProcProcess(t, procRoot, 201, "sshd", "S", 0, "/usr/sbin/sshd", "-D")
+	writeTestProcSocketFD(t, procRoot, 201, "3", "1002")
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckListen)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"TCP port listening", func(check *CheckListen) {}, true, ""},
+		{"TCP port on the address, owner and process", func(check *CheckListen) {
+			check.Address = net.ParseIP("127.0.0.1")
+			check.NoWildcard = true
+			check.uid = 1000
+			check.ProcessName = "app"
+		}, true, ""},
+		{"TCP port not listening", func(check *CheckListen) {
+			check.Port = 8081
+		}, false, "nothing is listening on tcp:8081"},
+		{"TCP port absent", func(check *CheckListen) {
+			check.Port = 8081
+			check.Absent = true
+		}, true, ""},
+		{"TCP port listening but should be absent", func(check *CheckListen) {
+			check.Absent = true
+		}, false, "tcp:8080 is listening but should not"},
+		{"TCP port on another address", func(check *CheckListen) {
+			check.Address = net.ParseIP("10.0.0.1")
+		}, false, "tcp:8080 is not listening on 10.0.0.1 but on 127.0.0.1"},
+		{"TCP port on any address", func(check *CheckListen) {
+			check.Port = 22
+			check.NoWildcard = true
+		}, false, "tcp:22 is listening on any address 0.0.0.0"},
+		{"TCP port on any IPv6 address", func(check *CheckListen) {
+			check.Port = 22
+			check.Address = net.ParseIP("::")
+			check.ProcessName = "sshd"
+		}, false, "owner process not found (inode 1004)"},
+		{"TCP port owner mismatch", func(check *CheckListen) {
+			check.uid = 0
+		}, false, "tcp:8080 owner mismatch want uid 0 got 1000"},
+		{"TCP port process mismatch", func(check *CheckListen) {
+			check.ProcessName = "nginx"
+		}, false, "tcp:8080 owner process 200 name mismatch want nginx got app"},
+		{"UDP port bound", func(check *CheckListen) {
+			check.Network, check.Port = "udp", 53
+			check.uid = 101
+		}, true, ""},
+		{"UDP port not bound", func(check *CheckListen) {
+			check.Network = "udp"
+		}, false, "nothing is listening on udp:8080"},
+		{"Unix socket listening", func(check *CheckListen) {
+			check.Network, check.Path = "unix", "/run/app.sock"
+			check.uid = 1000
+			check.ProcessName = "app"
+		}, true, ""},
+		{"Unix socket owner mismatch", func(check *CheckListen) {
+			check.Network, check.Path = "unix", "/run/app.sock"
+			check.uid = 0
+		}, false, "unix:/run/app.sock owner process 200 mismatch want uid 0 got 1000"},
+		{"Unix datagram socket", func(check *CheckListen) {
+			check.Network, check.Path = "unix", "/run/syslog.sock"
+		}, true, ""},
+		{"Unix socket missing", func(check *CheckListen) {
+			check.Network, check.Path = "unix", "/run/missing.sock"
+		}, false, "nothing is listening on unix:/run/missing.sock"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckListen(8080)
+			check.procRoot = procRoot
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) == 0 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package chkok
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CheckPIDFile checks for a PID file, and that its process is alive and optionally
+// is running the expected binary. File attributes are checked same as CheckFile.
+type CheckPIDFile struct {
+	CheckFile
+	ProcessName string // expected base name of the process executable or its short name (comm), empty to skip
+	procRoot    string
+}
+
+// NewCheckPIDFile returns a new CheckPIDFile for a regular file with an alive process
+func NewCheckPIDFile(path string) *CheckPIDFile {
+	chk := CheckPIDFile{CheckFile: *NewCheckFile(path), procRoot: "/proc"}
+	chk.fileType = TypeFile
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckPIDFile) Name() string {
+	return fmt.Sprintf("pidfile:%v", chk.path)
+}
+
+// Run runs the check
+func (chk *CheckPIDFile) Run() Result {
+	chk.CheckFile.Run()
+	if chk.absent || !chk.result.IsOK {
+		return chk.result
+	}
+
+	pid, err := readPIDFile(chk.path)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		return chk.result
+	}
+	process := procProcess{pid: pid}
+	dir := filepath.Join(chk.procRoot, strconv.Itoa(pid))
+	if err = readProcStatus(filepath.Join(dir, "status"), &process); err != nil || process.state == "Z" {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("process %v is not alive, pid file is stale", pid))
+		return chk.result
+	}
+	if chk.ProcessName != "" && process.name != chk.ProcessName && procExeName(dir) != chk.ProcessName {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"process %v name mismatch want %v got %v", pid, chk.ProcessName, process.name))
+	}
+	return chk.result
+}
+
+// procExeName returns base name of the executable of the process in the proc dir, empty if not accessible
+func procExeName(procDir string) string {
+	exe, err := os.Readlink(filepath.Join(procDir, "exe"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSuffix(exe, " (deleted)"))
+}
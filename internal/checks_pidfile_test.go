@@ -0,0 +1,79 @@
+package chkok
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCheckPIDFile(t *testing.T) {
+	check := NewCheckPIDFile("/no/such/path/exists")
+	want := "pidfile:/no/such/path/exists"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check pid file name, want %v got %v", want, got)
+	}
+	if check.Run().IsOK {
+		t.Error("invalid check pid file not exists, want not ok got ok")
+	}
+	check.absent = true
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("invalid check pid file absent, want ok got issues %v", result.Issues)
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "test.pid")
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write pid file: %v", err)
+	}
+	check = NewCheckPIDFile(pidFile)
+	exe, _ := os.Executable()
+	check.ProcessName = filepath.Base(exe)
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("check pid file of the running test, want ok got issues %v", result.Issues)
+	}
+}
+
+func TestCheckPIDFileProcess(t *testing.T) {
+	procRoot, dir := t.TempDir(), t.TempDir()
+	writeTestProcProcess(t, procRoot, 100, "worker", "S", 0, "/usr/bin/worker")
+	writeTestProcProcess(t, procRoot, 101, "worker", "Z", 0, "/usr/bin/worker")
+	pidFiles := map[string]string{"worker.pid": "100\n", "zombie.pid": "101", "stale.pid": "102", "invalid.pid": "worker"}
+	for name, content := range pidFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write pid file: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		pidFile     string
+		processName string
+		expectPass  bool
+		wantIssue   string
+	}{
+		{"Alive process", "worker.pid", "", true, ""},
+		{"Process name matches", "worker.pid", "worker", true, ""},
+		{"Process name mismatch", "worker.pid", "nginx", false, "process 100 name mismatch want nginx got worker"},
+		{"Zombie process", "zombie.pid", "", false, "process 101 is not alive"},
+		{"Stale pid file", "stale.pid", "", false, "process 102 is not alive"},
+		{"Invalid pid file", "invalid.pid", "", false, "has no valid pid"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckPIDFile(filepath.Join(dir, tc.pidFile))
+			check.procRoot = procRoot
+			check.ProcessName = tc.processName
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package chkok
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procClockTicks is the number of clock ticks per second (USER_HZ) of the cpu times in /proc/<pid>/stat
+const procClockTicks = 100
+
+// procProcess is the details of a process read from the proc filesystem
+type procProcess struct {
+	pid     int
+	name    string // short name (comm) of the process
+	cmdline string // command line arguments joined by space
+	state   string
+	uid     int
+	rss     uint64 // resident set size in bytes
+	cpuTime time.Duration
+}
+
+// CheckProcess checks for running processes matching a name, command line or a PID file,
+// their number of instances, owner and resource usage
+type CheckProcess struct {
+	baseCheck
+	ProcessName  string         // matches the process short name or base name of its executable, empty to skip
+	CmdlineRegex *regexp.Regexp // matches the full command line, nil to skip
+	PIDFile      string         // only match the process with the PID in the file, empty to skip
+	MinCount     int            // min number of matching processes
+	MaxCount     int            // max number of matching processes, -1 to skip
+	MaxRSS       uint64         // max resident set size in bytes of each process, 0 to skip
+	MaxCPUTime   time.Duration  // max cpu time (user and system) of each process, 0 to skip
+	uid          int            // expected owner of the processes, -1 to skip
+	procRoot     string
+}
+
+// NewCheckProcess returns a new CheckProcess for at least one process with the name
+func NewCheckProcess(name string) *CheckProcess {
+	return &CheckProcess{ProcessName: name, MinCount: 1, MaxCount: -1, uid: -1, procRoot: "/proc"}
+}
+
+// Name returns the unique name of the check
+func (chk *CheckProcess) Name() string {
+	var matchers []string
+	if chk.ProcessName != "" {
+		matchers = append(matchers, chk.ProcessName)
+	}
+	if chk.CmdlineRegex != nil {
+		matchers = append(matchers, chk.CmdlineRegex.String())
+	}
+	if chk.PIDFile != "" {
+		matchers = append(matchers, chk.PIDFile)
+	}
+	return fmt.Sprintf("process:%v", strings.Join(matchers, ","))
+}
+
+// Run runs the check and returns the results
+func (chk *CheckProcess) Run() Result {
+	if chk.ProcessName == "" && chk.CmdlineRegex == nil && chk.PIDFile == "" {
+		panic("check process has no name, command line or pid file to match")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	processes, err := chk.matchingProcesses()
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		return chk.result
+	}
+	count := 0
+	for index := range processes {
+		if processes[index].state == "Z" {
+			chk.result.IsOK = false
+			chk.result.Issues = append(chk.result.Issues, fmt.Errorf("process %v is a zombie", processes[index].pid))
+			continue
+		}
+		count++
+		chk.checkProcess(&processes[index], &chk.result)
+	}
+	chk.result.Metrics = append(chk.result.Metrics, Metric{Name: "count", Value: float64(count)})
+	if count < chk.MinCount {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"too few processes, found %v but minimum is %v", count, chk.MinCount))
+	}
+	if chk.MaxCount > -1 && count > chk.MaxCount {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"too many processes, found %v but maximum is %v", count, chk.MaxCount))
+	}
+	chk.status = StatusDone
+	return chk.result
+}
+
+// matchingProcesses returns the processes matching the name, command line and PID file
+func (chk *CheckProcess) matchingProcesses() ([]procProcess, error) {
+	var pids []int
+	var err error
+	if chk.PIDFile != "" {
+		var pid int
+		if pid, err = readPIDFile(chk.PIDFile); err != nil {
+			return nil, err
+		}
+		pids = []int{pid}
+	} else if pids, err = listProcPIDs(chk.procRoot); err != nil {
+		return nil, err
+	}
+	var processes []procProcess
+	for _, pid := range pids {
+		process, err := readProcProcess(chk.procRoot, pid)
+		if err != nil { // process is gone, or not accessible
+			continue
+		}
+		if chk.ProcessName != "" && process.name != chk.ProcessName &&
+			filepath.Base(strings.SplitN(process.cmdline, " ", 2)[0]) != chk.ProcessName {
+			continue
+		}
+		if chk.CmdlineRegex != nil && !chk.CmdlineRegex.MatchString(process.cmdline) {
+			continue
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// checkProcess checks owner and resource usage of the process and updates the provided result
+func (chk *CheckProcess) checkProcess(process *procProcess, result *Result) {
+	if chk.uid > -1 && process.uid != chk.uid {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"process %v owner mismatch want uid %v got %v", process.pid, chk.uid, process.uid))
+	}
+	if chk.MaxRSS > 0 && process.rss > chk.MaxRSS {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"process %v uses too much memory, rss %v bytes is more than max rss %v", process.pid, process.rss, chk.MaxRSS))
+	}
+	if chk.MaxCPUTime > 0 && process.cpuTime > chk.MaxCPUTime {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"process %v used too much cpu, cpu time %v is more than max cpu time %v",
+			process.pid, process.cpuTime, chk.MaxCPUTime))
+	}
+}
+
+// readPIDFile returns the PID in the file
+func readPIDFile(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid < 1 {
+		return 0, fmt.Errorf("pid file %v has no valid pid", path)
+	}
+	return pid, nil
+}
+
+// listProcPIDs returns the PIDs of all the processes in the proc filesystem
+func listProcPIDs(procRoot string) ([]int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// readProcProcess returns the details of the process from its status, stat and cmdline files in the proc filesystem
+func readProcProcess(procRoot string, pid int) (procProcess, error) {
+	process := procProcess{pid: pid, uid: -1}
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	if err := readProcStatus(filepath.Join(dir, "status"), &process); err != nil {
+		return process, err
+	}
+	if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil {
+		process.cpuTime = parseProcStatCPUTime(stat)
+	}
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return process, err
+	}
+	process.cmdline = strings.TrimSpace(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+	return process, nil
+}
+
+// readProcStatus reads the name, state, real uid and rss from the status file into the process
+func readProcStatus(path string, process *procProcess) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(value)
+		if !found || len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Name":
+			process.name = strings.TrimSpace(value)
+		case "State":
+			process.state = fields[0]
+		case "Uid":
+			process.uid, _ = strconv.Atoi(fields[0])
+		case "VmRSS": // in kB
+			if rss, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+				process.rss = rss * 1024
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	if process.name == "" {
+		return errors.New("process status has no name")
+	}
+	return nil
+}
+
+// parseProcStatCPUTime returns the user and system cpu time from the content of a stat file
+func parseProcStatCPUTime(stat []byte) time.Duration {
+	// the name (2nd field) can contain spaces and parentheses, so fields are after the last ')'
+	index := bytes.LastIndexByte(stat, ')')
+	if index == -1 {
+		return 0
+	}
+	fields := strings.Fields(string(stat[index+1:]))
+	if len(fields) < 13 { // utime and stime are 14th and 15th fields of the stat file
+		return 0
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	return time.Duration(utime+stime) * time.Second / procClockTicks //nolint: gosec
+}
@@ -0,0 +1,132 @@
+package chkok
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestProcProcess writes the status, stat and cmdline files of a process in a fake proc filesystem
+func writeTestProcProcess(t *testing.T, procRoot string, pid int, name, state string, uid int, cmdline ...string) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("Failed to create proc dir: %v", err)
+	}
+	status := fmt.Sprintf("Name:\t%s\nState:\t%s (state)\nUid:\t%d\t%d\t%d\t%d\nVmRSS:\t    2048 kB\n",
+		name, state, uid, uid, uid, uid)
+	// utime 150 and stime 50 ticks, 2 seconds of cpu time
+	stat := fmt.Sprintf("%d (%s) %s 1 1 1 0 -1 4194304 86 0 0 0 150 50 0 0 20 0 1 0 162177 2703360 512", pid, name, state)
+	files := map[string]string{
+		"status":  status,
+		"stat":    stat,
+		"cmdline": strings.Join(cmdline, "\x00") + "\x00",
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write proc file: %v", err)
+		}
+	}
+}
+
+func TestCheckProcess(t *testing.T) {
+	check := NewCheckProcess("worker")
+	check.CmdlineRegex = regexp.MustCompile("--queue=jobs")
+	want := "process:worker,--queue=jobs"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check process name, want %v got %v", want, got)
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "test.pid")
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write pid file: %v", err)
+	}
+	check = NewCheckProcess("")
+	check.PIDFile = pidFile
+	check.MaxCount = 1
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("check process of the running test pid file, want ok got issues %v", result.Issues)
+	}
+	check.PIDFile = filepath.Join(t.TempDir(), "missing.pid")
+	if result := check.Run(); result.IsOK {
+		t.Error("check process of a missing pid file, want not ok got ok")
+	}
+}
+
+func TestCheckProcessMatches(t *testing.T) {
+	procRoot := t.TempDir()
+	writeTestProcProcess(t, procRoot, 100, "worker", "S", 0, "/usr/bin/worker", "--queue=jobs")
+	writeTestProcProcess(t, procRoot, 101, "worker", "S", 1000, "/usr/bin/worker", "--queue=mail")
+	writeTestProcProcess(t, procRoot, 102, "python3", "R", 1000, "/usr/bin/python3", "/opt/app/scheduler.py")
+	writeTestProcProcess(t, procRoot, 103, "defunct", "Z", 1000, "/usr/sbin/defunct")
+	if err := os.WriteFile(filepath.Join(procRoot, "uptime"), []byte("1 1"), 0600); err != nil {
+		t.Fatalf("Failed to write proc file: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckProcess)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Name matches", func(check *CheckProcess) {}, true, ""},
+		{"Name not running", func(check *CheckProcess) {
+			check.ProcessName = "nginx"
+		}, false, "too few processes, found 0"},
+		{"Executable base name matches", func(check *CheckProcess) {
+			check.ProcessName = "python3"
+			check.MaxCount = 1
+		}, true, ""},
+		{"Too many instances", func(check *CheckProcess) {
+			check.MaxCount = 1
+		}, false, "too many processes, found 2"},
+		{"Cmdline regex matches", func(check *CheckProcess) {
+			check.ProcessName = ""
+			check.CmdlineRegex = regexp.MustCompile(`scheduler\.py$`)
+		}, true, ""},
+		{"Name and cmdline regex match exactly one", func(check *CheckProcess) {
+			check.CmdlineRegex = regexp.MustCompile("--queue=jobs")
+			check.MinCount, check.MaxCount = 1, 1
+		}, true, ""},
+		{"Owner matches", func(check *CheckProcess) {
+			check.CmdlineRegex = regexp.MustCompile("--queue=jobs")
+			check.uid = 0
+		}, true, ""},
+		{"Owner mismatch", func(check *CheckProcess) {
+			check.CmdlineRegex = regexp.MustCompile("--queue=mail")
+			check.uid = 0
+		}, false, "process 101 owner mismatch want uid 0 got 1000"},
+		{"Max rss", func(check *CheckProcess) {
+			check.MaxRSS = 1024 * 1024
+		}, false, "process 100 uses too much memory"},
+		{"Max cpu time", func(check *CheckProcess) {
+			check.MaxCPUTime = time.Second
+			check.CmdlineRegex = regexp.MustCompile("--queue=jobs")
+		}, false, "cpu time 2s is more than max cpu time 1s"},
+		{"Zombie", func(check *CheckProcess) {
+			check.ProcessName = "defunct"
+			check.MinCount = 0
+		}, false, "process 103 is a zombie"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckProcess("worker")
+			check.procRoot = procRoot
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) == 0 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package chkok
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemdProperties are the unit properties queried by CheckSystemd
+var systemdProperties = []string{"LoadState", "ActiveState", "SubState", "UnitFileState", "NRestarts"}
+
+// CheckSystemd checks for the state of a systemd unit, using systemctl to query the unit properties
+type CheckSystemd struct {
+	baseCheck
+	Unit        string
+	ActiveState string // expected ActiveState, e.g. active or inactive
+	SubState    string // expected SubState, e.g. running or exited, empty to skip
+	Enabled     *bool  // if the unit file should be enabled or not, nil to skip
+	MaxRestarts int    // max number of restarts of the unit service (NRestarts), -1 to skip
+	Systemctl   string // path to the systemctl command
+	timeout     time.Duration
+}
+
+// NewCheckSystemd returns a new CheckSystemd for an active unit
+func NewCheckSystemd(unit string) *CheckSystemd {
+	chk := CheckSystemd{Unit: unit, ActiveState: "active", MaxRestarts: -1, Systemctl: "systemctl"}
+	chk.SetTimeout(5 * time.Second)
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckSystemd) Name() string {
+	return fmt.Sprintf("systemd:%v", chk.Unit)
+}
+
+// GetTimeout returns the timeout of the check
+func (chk *CheckSystemd) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the timeout of the check
+func (chk *CheckSystemd) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
+// Run runs the check and returns the results
+func (chk *CheckSystemd) Run() Result {
+	if chk.Unit == "" {
+		panic("check systemd unit is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	properties, err := chk.unitProperties()
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		if errors.Is(err, context.DeadlineExceeded) {
+			chk.status = StatusStopped
+		}
+		return chk.result
+	}
+
+	if properties["LoadState"] == "not-found" && chk.ActiveState != "inactive" {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("unit %v not found", chk.Unit))
+		chk.status = StatusDone
+		return chk.result
+	}
+	if got := properties["ActiveState"]; chk.ActiveState != "" && got != chk.ActiveState {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf(
+			"active state mismatch want %v got %v", chk.ActiveState, got))
+	}
+	if got := properties["SubState"]; chk.SubState != "" && got != chk.SubState {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("sub state mismatch want %v got %v", chk.SubState, got))
+	}
+	unitFileState := properties["UnitFileState"]
+	enabled := unitFileState == "enabled" || unitFileState == "enabled-runtime"
+	if chk.Enabled != nil && enabled != *chk.Enabled {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("unit enabled mismatch want %v got %v (%v)",
+			*chk.Enabled, enabled, unitFileState))
+	}
+	chk.checkRestarts(properties["NRestarts"], &chk.result)
+	chk.status = StatusDone
+	return chk.result
+}
+
+// checkRestarts checks number of the restarts of the unit and updates the provided result
+func (chk *CheckSystemd) checkRestarts(nRestarts string, result *Result) {
+	if nRestarts == "" { // not a service unit, or systemd is older than v235
+		return
+	}
+	restarts, err := strconv.Atoi(nRestarts)
+	if err != nil {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf("invalid number of restarts %q: %v", nRestarts, err))
+		return
+	}
+	result.Metrics = append(result.Metrics, Metric{Name: "restarts", Value: float64(restarts)})
+	if chk.MaxRestarts > -1 && restarts > chk.MaxRestarts {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"unit restarted too many times, restarted %v times but maximum is %v", restarts, chk.MaxRestarts))
+	}
+}
+
+// unitProperties returns the properties of the unit queried by systemctl show
+func (chk *CheckSystemd) unitProperties() (map[string]string, error) {
+	ctx := context.Background()
+	if chk.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, chk.timeout)
+		defer cancel()
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, chk.Systemctl, "show", chk.Unit, //nolint: gosec
+		"--property="+strings.Join(systemdProperties, ","))
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second // don't wait on output of child processes after the timeout
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%v show timed out: %w", chk.Systemctl, ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v show failed: %v %v", chk.Systemctl, err, strings.TrimSpace(stderr.String()))
+	}
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if key, value, found := strings.Cut(scanner.Text(), "="); found {
+			properties[key] = value
+		}
+	}
+	return properties, scanner.Err()
+}
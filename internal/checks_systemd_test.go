@@ -0,0 +1,120 @@
+package chkok
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestSystemctl writes a stub systemctl command printing the properties of units by name
+func writeTestSystemctl(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "systemctl")
+	script := `#!/bin/sh
+case "$2" in
+  nginx.service) printf 'LoadState=loaded\nActiveState=active\nSubState=running\n'
+    printf 'UnitFileState=enabled\nNRestarts=0\n' ;;
+  flaky.service) printf 'LoadState=loaded\nActiveState=active\nSubState=running\n'
+    printf 'UnitFileState=disabled\nNRestarts=5\n' ;;
+  stopped.service) printf 'LoadState=loaded\nActiveState=inactive\nSubState=dead\nUnitFileState=disabled\n' ;;
+  missing.service) printf 'LoadState=not-found\nActiveState=inactive\nSubState=dead\n' ;;
+  slow.service) exec sleep 5 ;;
+  *) echo "failed to connect to bus" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil { //nolint: gosec
+		t.Fatalf("Failed to write stub systemctl: %v", err)
+	}
+	return path
+}
+
+func TestCheckSystemd(t *testing.T) {
+	check := NewCheckSystemd("nginx.service")
+	want := "systemd:nginx.service"
+	if got := check.Name(); got != want {
+		t.Errorf("invalid check systemd name, want %v got %v", want, got)
+	}
+	check.Systemctl = "/no/such/path/exists"
+	if result := check.Run(); result.IsOK || check.Status() != StatusDone {
+		t.Errorf("invalid check systemd command not exists, want not ok and done, got %v %v", result.IsOK, check.Status())
+	}
+}
+
+func TestCheckSystemdUnitState(t *testing.T) {
+	systemctl := writeTestSystemctl(t)
+	enabled, disabled := true, false
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckSystemd)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Active unit", func(check *CheckSystemd) {}, true, ""},
+		{"Running and enabled", func(check *CheckSystemd) {
+			check.SubState = "running"
+			check.Enabled = &enabled
+			check.MaxRestarts = 0
+		}, true, ""},
+		{"Not enabled", func(check *CheckSystemd) {
+			check.Unit = "flaky.service"
+			check.Enabled = &enabled
+		}, false, "unit enabled mismatch want true got false (disabled)"},
+		{"Restarted too many times", func(check *CheckSystemd) {
+			check.Unit = "flaky.service"
+			check.MaxRestarts = 3
+		}, false, "restarted 5 times but maximum is 3"},
+		{"Not active", func(check *CheckSystemd) {
+			check.Unit = "stopped.service"
+		}, false, "active state mismatch want active got inactive"},
+		{"Must be stopped", func(check *CheckSystemd) {
+			check.Unit = "stopped.service"
+			check.ActiveState = "inactive"
+			check.Enabled = &disabled
+		}, true, ""},
+		{"Sub state mismatch", func(check *CheckSystemd) {
+			check.SubState = "exited"
+		}, false, "sub state mismatch want exited got running"},
+		{"Unit not found", func(check *CheckSystemd) {
+			check.Unit = "missing.service"
+		}, false, "unit missing.service not found"},
+		{"Unit not found must be stopped", func(check *CheckSystemd) {
+			check.Unit = "missing.service"
+			check.ActiveState = "inactive"
+		}, true, ""},
+		{"Systemctl fails", func(check *CheckSystemd) {
+			check.Unit = "other.service"
+		}, false, "failed to connect to bus"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckSystemd("nginx.service")
+			check.Systemctl = systemctl
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestCheckSystemdTimeout(t *testing.T) {
+	check := NewCheckSystemd("slow.service")
+	check.Systemctl = writeTestSystemctl(t)
+	check.SetTimeout(100 * time.Millisecond)
+	start := time.Now()
+	result := check.Run()
+	if result.IsOK || check.Status() != StatusStopped {
+		t.Errorf("check systemd timeout want not ok and stopped, got %v %v", result.IsOK, check.Status())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("check systemd timeout want to stop after timeout, took %v", elapsed)
+	}
+}
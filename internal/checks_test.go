@@ -1,9 +1,14 @@
 package chkok
 
 import (
+	"crypto/md5" //nolint: gosec
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -261,3 +266,639 @@ func TestCheckDialTCPPort(t *testing.T) {
 		t.Errorf("invalid check dial status, want %v got %v", wantStatus, gotStatus)
 	}
 }
+
+// serveTestUDPEcho listens on a local udp port replying with the received payload
+// when reply is true, and returns the address of the listener
+func serveTestUDPEcho(t *testing.T, reply bool) string {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on udp: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply {
+				_, _ = conn.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestCheckDialNetworks(t *testing.T) {
+	tcpListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on tcp: %v", err)
+	}
+	defer tcpListener.Close()
+	socketPath := filepath.Join(t.TempDir(), "test.sock")
+	unixListener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on unix socket: %v", err)
+	}
+	defer unixListener.Close()
+	datagramPath := filepath.Join(t.TempDir(), "test-dgram.sock")
+	datagramConn, err := net.ListenPacket("unixgram", datagramPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on unixgram socket: %v", err)
+	}
+	defer datagramConn.Close()
+	echoAddress := serveTestUDPEcho(t, true)
+	silentAddress := serveTestUDPEcho(t, false)
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckDial)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"TCP4 listening", func(check *CheckDial) {
+			check.Network, check.Address = "tcp4", tcpListener.Addr().String()
+		}, true, ""},
+		{"Unix socket listening", func(check *CheckDial) {
+			check.Network, check.Address = "unix", socketPath
+		}, true, ""},
+		{"Unix socket missing", func(check *CheckDial) {
+			check.Network, check.Address = "unix", filepath.Join(t.TempDir(), "missing.sock")
+		}, false, "no such file"},
+		{"Unixgram socket listening", func(check *CheckDial) {
+			check.Network, check.Address = "unixgram", datagramPath
+		}, true, ""},
+		{"UDP replies", func(check *CheckDial) {
+			check.Network, check.Address = "udp", echoAddress
+			check.Send = []byte("ping")
+		}, true, ""},
+		{"UDP no reply", func(check *CheckDial) {
+			check.Network, check.Address = "udp4", silentAddress
+			check.Send = []byte("ping")
+		}, false, "no reply from"},
+		{"UDP no reply is absent", func(check *CheckDial) {
+			check.Network, check.Address = "udp", silentAddress
+			check.Absent = true
+		}, true, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckDial()
+			check.SetTimeout(300 * time.Millisecond)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+// serveTestTCP listens on a local tcp port, serving each connection with the handler,
+// and returns the address of the listener
+func serveTestTCP(t *testing.T, handler func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen on tcp: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCheckDialSendExpect(t *testing.T) {
+	bannerAddress := serveTestTCP(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("SSH-2.0-"))
+		time.Sleep(20 * time.Millisecond) // banner arrives in parts
+		_, _ = conn.Write([]byte("OpenSSH_9.6\r\n"))
+	})
+	redisAddress := serveTestTCP(t, func(conn net.Conn) {
+		buf := make([]byte, 64)
+		if n, _ := conn.Read(buf); string(buf[:n]) == "PING\r\n" {
+			_, _ = conn.Write([]byte("+PONG\r\n"))
+		} else {
+			_, _ = conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		time.Sleep(time.Second) // keep the connection open
+	})
+	silentAddress := serveTestTCP(t, func(conn net.Conn) {
+		time.Sleep(time.Second)
+	})
+	echoAddress := serveTestUDPEcho(t, true)
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckDial)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Banner contains", func(check *CheckDial) {
+			check.Address = bannerAddress
+			check.Expect = []byte("OpenSSH")
+		}, true, ""},
+		{"Banner regex", func(check *CheckDial) {
+			check.Address = bannerAddress
+			check.ExpectRegex = regexp.MustCompile(`^SSH-2\.0-\S+\r\n$`)
+		}, true, ""},
+		{"Banner mismatch", func(check *CheckDial) {
+			check.Address = bannerAddress
+			check.Expect = []byte("SSH-1.99")
+		}, false, `unexpected reply from 127.0.0.1`},
+		{"Send and expect", func(check *CheckDial) {
+			check.Address = redisAddress
+			check.Send = []byte("PING\r\n")
+			check.Expect = []byte("+PONG\r\n")
+		}, true, ""},
+		{"Send and unexpected reply", func(check *CheckDial) {
+			check.Address = redisAddress
+			check.Send = []byte("QUIT\r\n")
+			check.Expect = []byte("+PONG")
+		}, false, `"-ERR unknown command\r\n"`},
+		{"No reply", func(check *CheckDial) {
+			check.Address = silentAddress
+			check.ExpectRegex = regexp.MustCompile(`^220 `)
+		}, false, "no reply from"},
+		{"Send only", func(check *CheckDial) {
+			check.Address = silentAddress
+			check.Send = []byte("hello")
+		}, true, ""},
+		{"UDP reply expected", func(check *CheckDial) {
+			check.Network, check.Address = "udp", echoAddress
+			check.Send = []byte("ping\x00")
+			check.Expect = []byte("ping\x00")
+		}, true, ""},
+		{"UDP reply mismatch", func(check *CheckDial) {
+			check.Network, check.Address = "udp", echoAddress
+			check.Send = []byte("ping")
+			check.ExpectRegex = regexp.MustCompile("^pong$")
+		}, false, `unexpected reply from 127.0.0.1`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckDial()
+			check.SetTimeout(300 * time.Millisecond)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) == 0 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestCheckFileMode(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "mode-test")
+	if err := os.WriteFile(filePath, []byte("test"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(filePath, 0o750|os.ModeSetuid); err != nil { // chmod to skip umask
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		mode       int32
+		minMode    int32
+		maxMode    int32
+		expectPass bool
+		wantIssue  string
+	}{
+		{"No constraints", -1, -1, -1, true, ""},
+		{"Exact mode satisfied", 0o4750, -1, -1, true, ""},
+		{"Exact mode not satisfied", 0o750, -1, -1, false, "mode mismatch want 0750 got 4750"},
+		{"Min mode satisfied", -1, 0o4700, -1, true, ""},
+		{"Min mode not satisfied", -1, 0o644, -1, false, "want at least 0644 got 4750"},
+		{"Max mode satisfied", -1, -1, 0o4755, true, ""},
+		{"Max mode setuid not satisfied", -1, -1, 0o755, false, "want at most 0755 got 4750"},
+		{"Max mode not satisfied", -1, -1, 0o4700, false, "want at most 4700 got 4750"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(filePath)
+			check.mode = tc.mode
+			check.minMode = tc.minMode
+			check.maxMode = tc.maxMode
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestCheckFileMetrics(t *testing.T) {
+	check := NewCheckFile("../LICENSE")
+	result := check.Run()
+	if len(result.Metrics) != 1 || result.Metrics[0].Name != "size" || result.Metrics[0].Value <= 0 {
+		t.Errorf("check file want size metric, got %+v", result.Metrics)
+	}
+	dirCheck := NewCheckFile("../cmd")
+	dirCheck.fileType = TypeDir
+	dirCheck.maxFileCount = 100
+	result = dirCheck.Run()
+	if len(result.Metrics) != 1 || result.Metrics[0].Name != "file_count" || result.Metrics[0].Value <= 0 {
+		t.Errorf("check dir want file count metric, got %+v", result.Metrics)
+	}
+}
+
+func TestCheckFileWarningThresholds(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 5; i++ {
+		filename := filepath.Join(tempDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(filename, []byte("test"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		setup       func(check *CheckFile)
+		expectPass  bool
+		wantWarning string
+	}{
+		{"Below warning max file count", func(check *CheckFile) {
+			check.warnMaxFileCount = 10
+		}, true, ""},
+		{"Over warning max file count", func(check *CheckFile) {
+			check.warnMaxFileCount, check.maxFileCount = 3, 10
+		}, true, "directory contains many files"},
+		{"Over max file count only fails", func(check *CheckFile) {
+			check.warnMaxFileCount, check.maxFileCount = 3, 4
+		}, false, ""},
+		{"Under warning min file count", func(check *CheckFile) {
+			check.warnMinFileCount = 6
+		}, true, "directory contains few files"},
+		{"Over warning max size", func(check *CheckFile) {
+			check.path = filepath.Join(tempDir, "file0.txt")
+			check.fileType = TypeFile
+			check.warnMaxSize = 2
+		}, true, "file is large"},
+		{"Under warning min size", func(check *CheckFile) {
+			check.path = filepath.Join(tempDir, "file0.txt")
+			check.fileType = TypeFile
+			check.warnMinSize = 10
+		}, true, "file is small"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(tempDir)
+			check.fileType = TypeDir
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantWarning == "" && len(result.Warnings) > 0 {
+				t.Errorf("Expected no warnings, got %v", result.Warnings)
+			}
+			if tc.wantWarning != "" &&
+				(len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Error(), tc.wantWarning)) {
+				t.Errorf("Expected 1 warning with %q, got %v", tc.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestCheckFileContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sshd_config")
+	content := "Port 22\nPermitRootLogin no\nPasswordAuthentication no\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckFile)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Contains", func(check *CheckFile) {
+			check.contains = []string{"PermitRootLogin no", "Port 22"}
+		}, true, ""},
+		{"Doesn't contain", func(check *CheckFile) {
+			check.contains = []string{"PermitRootLogin no", "UsePAM yes"}
+		}, false, "file doesn't contain \"UsePAM yes\""},
+		{"Not contains", func(check *CheckFile) {
+			check.notContains = []string{"PermitRootLogin yes"}
+		}, true, ""},
+		{"Contains unwanted", func(check *CheckFile) {
+			check.notContains = []string{"PasswordAuthentication no"}
+		}, false, "file contains \"PasswordAuthentication no\""},
+		{"Regex matches", func(check *CheckFile) {
+			check.regex = regexp.MustCompile(`(?m)^PermitRootLogin\s+no$`)
+		}, true, ""},
+		{"Regex doesn't match", func(check *CheckFile) {
+			check.regex = regexp.MustCompile(`(?m)^Port\s+2222$`)
+		}, false, "file doesn't match regex"},
+		{"Contains across lines", func(check *CheckFile) {
+			check.contains = []string{"Port 22\nPermitRootLogin no"}
+		}, false, "file doesn't contain"},
+		{"Regex matches a line", func(check *CheckFile) {
+			check.regex = regexp.MustCompile(`^PasswordAuthentication\s+no$`)
+		}, true, ""},
+		{"Content after max read size", func(check *CheckFile) {
+			check.notContains = []string{"PasswordAuthentication yes"}
+			check.maxReadSize = 10
+		}, false, "file is larger than max read size 10"},
+		{"Content of max read size", func(check *CheckFile) {
+			check.contains = []string{"PasswordAuthentication no"}
+			check.maxReadSize = int64(len(content))
+		}, true, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(path)
+			check.fileType = TypeFile
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestCheckFileDigests(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.bin")
+	content := []byte("binary content\n")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	sha256Sum, md5Sum := sha256.Sum256(content), md5.Sum(content) //nolint: gosec
+	sha256Digest, md5Digest := hex.EncodeToString(sha256Sum[:]), hex.EncodeToString(md5Sum[:])
+	otherDigest := strings.Repeat("0", 64)
+	if err := os.WriteFile(filepath.Join(dir, "other.bin"), content, 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	manifest := fmt.Sprintf("# deployed files\n%s  app.bin\n%s *%s\n",
+		sha256Digest, otherDigest, filepath.Join(dir, "other.bin"))
+	checksumFile := filepath.Join(dir, "SHA256SUMS")
+	if err := os.WriteFile(checksumFile, []byte(manifest), 0600); err != nil {
+		t.Fatalf("Failed to create checksum file: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckFile)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Digests match", func(check *CheckFile) {
+			check.digests = []FileDigest{{"sha256", sha256Digest}, {"md5", md5Digest}}
+		}, true, ""},
+		{"Digest mismatch", func(check *CheckFile) {
+			check.digests = []FileDigest{{"sha256", otherDigest}}
+		}, false, "sha256 digest mismatch want " + otherDigest + " got " + sha256Digest},
+		{"Checksum file matches", func(check *CheckFile) {
+			check.checksumFile = checksumFile
+		}, true, ""},
+		{"Checksum file with relative paths", func(check *CheckFile) {
+			cwd, _ := os.Getwd()
+			check.path, _ = filepath.Rel(cwd, path)
+			check.path = "." + string(filepath.Separator) + check.path
+			check.checksumFile = checksumFile
+		}, true, ""},
+		{"Checksum file mismatch", func(check *CheckFile) {
+			check.path = filepath.Join(dir, "other.bin")
+			check.checksumFile = checksumFile
+		}, false, "sha256 digest mismatch"},
+		{"Checksum file missing the file", func(check *CheckFile) {
+			check.path = checksumFile
+			check.checksumFile = checksumFile
+		}, false, "has no digest for"},
+		{"Checksum file not exists", func(check *CheckFile) {
+			check.checksumFile = filepath.Join(dir, "missing")
+		}, false, "no such file or directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(path)
+			check.fileType = TypeFile
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestNewFileDigest(t *testing.T) {
+	digest, err := NewFileDigest("md5", " D41D8CD98F00B204E9800998ECF8427E ")
+	if err != nil || digest.Digest != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Errorf("new file digest want normalized md5 digest, got %+v err %v", digest, err)
+	}
+	invalid := []FileDigest{
+		{"sha1", "da39a3ee5e6b4b0d3255bfef95601890afd80709"}, {"sha256", "d41d8cd98f00"}, {"md5", "xyz"},
+	}
+	for _, digest := range invalid {
+		if _, err = NewFileDigest(digest.Algorithm, digest.Digest); err == nil {
+			t.Errorf("new file digest %+v want err, got nil", digest)
+		}
+	}
+}
+
+func TestCheckFileAge(t *testing.T) {
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "backup.tar"), filepath.Join(dir, "heartbeat")
+	for _, path := range []string{oldPath, newPath} {
+		if err := os.WriteFile(path, []byte("test"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	old := time.Now().Add(-30 * time.Hour)
+	if err := os.Chtimes(oldPath, time.Now(), old); err != nil {
+		t.Fatalf("Failed to set file times: %v", err)
+	}
+	emptyDir := filepath.Join(dir, "empty")
+	if err := os.Mkdir(emptyDir, 0750); err != nil {
+		t.Fatalf("Failed to create test dir: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckFile)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Max age satisfied", func(check *CheckFile) {
+			check.path = newPath
+			check.maxAge = 26 * time.Hour
+		}, true, ""},
+		{"Max age not satisfied", func(check *CheckFile) {
+			check.maxAge = 26 * time.Hour
+		}, false, "file too old, mtime age 30h0m"},
+		{"Max age of atime", func(check *CheckFile) {
+			check.ageOf = FileTimeAccess
+			check.maxAge = 26 * time.Hour
+		}, true, ""},
+		{"Min age satisfied", func(check *CheckFile) {
+			check.minAge = time.Hour
+		}, true, ""},
+		{"Min age of ctime not satisfied", func(check *CheckFile) {
+			check.ageOf = FileTimeChange
+			check.minAge = time.Hour
+		}, false, "file too new, ctime age"},
+		{"Newest entry young enough", func(check *CheckFile) {
+			check.path = dir
+			check.fileType = TypeDir
+			check.newestMaxAge = time.Hour
+		}, true, ""},
+		{"Newest entry too old", func(check *CheckFile) {
+			check.path = dir
+			check.fileType = TypeDir
+			check.newestMaxAge = time.Nanosecond
+		}, false, "newest directory entry too old"},
+		{"Newest entry of empty directory", func(check *CheckFile) {
+			check.path = emptyDir
+			check.fileType = TypeDir
+			check.newestMaxAge = time.Hour
+		}, false, "directory has no entries"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(oldPath)
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestCheckFileDirScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{"a.log": 10, "b.txt": 20, "sub/c.log": 30, "sub/deep/d.log": 40}
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatalf("Failed to create test dir: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		setup         func(check *CheckFile)
+		expectPass    bool
+		wantCount     float64
+		wantTotalSize float64
+	}{
+		{"Entries", func(check *CheckFile) {}, true, 3, 30},
+		{"Entries included", func(check *CheckFile) {
+			check.include = "*.log"
+		}, true, 1, 10},
+		{"Recursive", func(check *CheckFile) {
+			check.recursive = true
+		}, true, 4, 100},
+		{"Recursive included", func(check *CheckFile) {
+			check.recursive = true
+			check.include = "*.log"
+		}, true, 3, 80},
+		{"Recursive max depth", func(check *CheckFile) {
+			check.recursive = true
+			check.maxDepth = 2
+		}, true, 3, 60},
+		{"Recursive too many files", func(check *CheckFile) {
+			check.recursive = true
+			check.maxFileCount = 3
+		}, false, 4, 100},
+		{"Recursive total size too large", func(check *CheckFile) {
+			check.recursive = true
+			check.maxTotalSize = 99
+		}, false, 4, 100},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckFile(dir)
+			check.fileType = TypeDir
+			check.minFileCount = 1
+			if check.maxTotalSize < 0 {
+				check.maxTotalSize = 1000
+			}
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			want := []Metric{{Name: "file_count", Value: tc.wantCount}, {Name: "total_size", Value: tc.wantTotalSize, Unit: "B"}}
+			if len(result.Metrics) != 2 || result.Metrics[0] != want[0] || result.Metrics[1] != want[1] {
+				t.Errorf("Expected metrics %v, got %v", want, result.Metrics)
+			}
+		})
+	}
+}
+
+func TestCheckFileDirScanTimeout(t *testing.T) {
+	check := NewCheckFile("..")
+	check.fileType = TypeDir
+	check.recursive = true
+	check.maxFileCount = 1000000
+	check.SetTimeout(time.Nanosecond)
+	result := check.Run()
+	if result.IsOK || check.Status() != StatusStopped {
+		t.Errorf("check file dir scan timeout want not ok and stopped, got %v %v", result.IsOK, check.Status())
+	}
+	if len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), "directory scan timed out") {
+		t.Errorf("Expected 1 issue with directory scan timed out, got %v", result.Issues)
+	}
+}
@@ -0,0 +1,148 @@
+package chkok
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// CheckTLS checks for a TLS service by dialing and performing a TLS handshake,
+// verifying the certificate chain, hostname and expiry of the server certificate.
+// Expiry is checked separately from the chain, so expired certificates are reported as such.
+type CheckTLS struct {
+	baseCheck
+	Address     string         // host:port of the tcp service
+	ServerName  string         // SNI and hostname to verify, defaults to host of the address
+	RootCAs     *x509.CertPool // nil to use the system CAs
+	MinValidFor time.Duration  // min duration the certificate should be valid for, 0 to skip
+	timeout     time.Duration
+}
+
+// NewCheckTLS returns a CheckTLS for local https availability by default
+func NewCheckTLS() *CheckTLS {
+	chk := CheckTLS{Address: "127.0.0.1:443"}
+	chk.SetTimeout(5 * time.Second)
+	return &chk
+}
+
+// Name returns the unique name of the check
+func (chk *CheckTLS) Name() string {
+	return fmt.Sprintf("tls:%v", chk.Address)
+}
+
+// GetTimeout returns the timeout of the check
+func (chk *CheckTLS) GetTimeout() time.Duration {
+	return chk.timeout
+}
+
+// SetTimeout sets the timeout of the check
+func (chk *CheckTLS) SetTimeout(timeout time.Duration) {
+	chk.timeout = timeout
+}
+
+// Run runs the check and returns the results
+func (chk *CheckTLS) Run() Result {
+	if chk.Address == "" {
+		panic("check tls address is empty")
+	}
+
+	chk.status = StatusRunning
+	chk.result = Result{IsOK: true, Issues: []error{}}
+	serverName := chk.ServerName
+	if serverName == "" {
+		if host, _, err := net.SplitHostPort(chk.Address); err == nil {
+			serverName = host
+		}
+	}
+	dialer := &net.Dialer{Timeout: chk.timeout}
+	config := &tls.Config{
+		ServerName:         serverName,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, //nolint: gosec // verified by VerifyPeerCertificate, except the expiry
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyPeerCertificate(rawCerts, serverName, chk.RootCAs)
+		},
+	}
+	start := time.Now()
+	conn, err := tls.DialWithDialer(dialer, "tcp", chk.Address, config)
+	if err != nil {
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, err)
+		chk.status = StatusDone
+		if isTimeoutError(err) {
+			chk.status = StatusStopped
+		}
+		return chk.result
+	}
+	defer conn.Close()
+	elapsed := time.Since(start)
+	if chk.timeout > 0 && elapsed > chk.timeout {
+		chk.status = StatusStopped
+		chk.result.IsOK = false
+		chk.result.Issues = append(chk.result.Issues, fmt.Errorf("check tls timed out after %v seconds", elapsed.Seconds()))
+		return chk.result
+	}
+
+	checkCertExpiry(conn.ConnectionState().PeerCertificates[0], chk.MinValidFor, &chk.result)
+	chk.status = StatusDone
+	return chk.result
+}
+
+// verifyPeerCertificate verifies the certificate chain (with the intermediate certificates sent by the
+// server) and the hostname of the server certificate, as valid at its expiry time if it's expired
+func verifyPeerCertificate(rawCerts [][]byte, serverName string, rootCAs *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server provided no certificates")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	opts := x509.VerifyOptions{DNSName: serverName, Roots: rootCAs, Intermediates: x509.NewCertPool()}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if time.Now().After(certs[0].NotAfter) { // expiry is checked after the handshake
+		opts.CurrentTime = certs[0].NotAfter
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify certificate: %w", err)
+	}
+	return nil
+}
+
+// checkCertExpiry checks the certificate is valid for at least the min duration and updates the provided result
+func checkCertExpiry(cert *x509.Certificate, minValidFor time.Duration, result *Result) {
+	validFor := time.Until(cert.NotAfter)
+	if validFor <= 0 {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"certificate %q expired at %v", cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339)))
+	} else if minValidFor > 0 && validFor < minValidFor {
+		result.IsOK = false
+		result.Issues = append(result.Issues, fmt.Errorf(
+			"certificate %q expires in %d days at %v, want valid for at least %v",
+			cert.Subject.CommonName, int(validFor.Hours()/24), cert.NotAfter.Format(time.RFC3339), minValidFor))
+	}
+}
+
+// loadCertPool loads the PEM certificates of the CA bundle file into a cert pool
+func loadCertPool(path string) (*x509.CertPool, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(contents) {
+		return nil, fmt.Errorf("no PEM certificates found in %v", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,124 @@
+package chkok
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// httptest TLS server certificate is valid for example.com and 127.0.0.1 until 2084
+const testTLSServerName = "example.com"
+
+func newTestTLSServer(t *testing.T) (server *httptest.Server, caFile string) {
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	caFile = filepath.Join(t.TempDir(), "ca.pem")
+	contents := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, contents, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	return server, caFile
+}
+
+func TestCheckTLS(t *testing.T) {
+	var check *CheckTLS
+	var got, want string
+	check = NewCheckTLS()
+	want = "tls:127.0.0.1:443"
+	if got = check.Name(); got != want {
+		t.Errorf("invalid check tls name, want %v got %v", want, got)
+	}
+	if gotStatus := check.Status(); gotStatus != StatusUnknown {
+		t.Errorf("invalid check tls status, want %v got %v", StatusUnknown, gotStatus)
+	}
+}
+
+func TestCheckTLSHandshake(t *testing.T) {
+	server, caFile := newTestTLSServer(t)
+	defer server.Close()
+	rootCAs, err := loadCertPool(caFile)
+	if err != nil {
+		t.Fatalf("Failed to load CA file: %v", err)
+	}
+	address := server.Listener.Addr().String()
+
+	testCases := []struct {
+		name       string
+		setup      func(check *CheckTLS)
+		expectPass bool
+		wantIssue  string
+	}{
+		{"Valid certificate", func(check *CheckTLS) {}, true, ""},
+		{"Valid certificate with SNI", func(check *CheckTLS) {
+			check.ServerName = testTLSServerName
+		}, true, ""},
+		{"Hostname mismatch", func(check *CheckTLS) {
+			check.ServerName = "wrong.example.org"
+		}, false, "certificate is valid for"},
+		{"Unknown authority", func(check *CheckTLS) {
+			check.RootCAs = nil
+		}, false, "certificate"},
+		{"Valid for long enough", func(check *CheckTLS) {
+			check.MinValidFor = 336 * time.Hour
+		}, true, ""},
+		{"Expires too soon", func(check *CheckTLS) {
+			check.MinValidFor = 100 * 365 * 24 * time.Hour
+		}, false, "want valid for at least"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := NewCheckTLS()
+			check.Address = address
+			check.RootCAs = rootCAs
+			tc.setup(check)
+
+			result := check.Run()
+
+			if result.IsOK != tc.expectPass {
+				t.Errorf("Expected IsOK=%v but got %v. Issues: %v", tc.expectPass, result.IsOK, result.Issues)
+			}
+			if tc.wantIssue != "" && (len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), tc.wantIssue)) {
+				t.Errorf("Expected 1 issue with %q, got %v", tc.wantIssue, result.Issues)
+			}
+			if check.Status() != StatusDone {
+				t.Errorf("invalid check tls status, want %v got %v", StatusDone, check.Status())
+			}
+		})
+	}
+}
+
+func TestCheckTLSExpired(t *testing.T) {
+	certPath, keyPath := writeTestCertFile(t, t.TempDir(), -time.Minute)
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	rootCAs, err := loadCertPool(certPath)
+	if err != nil {
+		t.Fatalf("Failed to load CA file: %v", err)
+	}
+
+	check := NewCheckTLS()
+	check.Address = server.Listener.Addr().String()
+	check.RootCAs = rootCAs
+	result := check.Run()
+	if result.IsOK || len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), "expired at") {
+		t.Errorf("check tls of expired certificate want 1 expired issue, got %v", result.Issues)
+	}
+
+	check.ServerName = "wrong.example.org"
+	result = check.Run()
+	if result.IsOK || len(result.Issues) != 1 || !strings.Contains(result.Issues[0].Error(), "certificate is valid for") {
+		t.Errorf("check tls of expired certificate with hostname mismatch want 1 hostname issue, got %v", result.Issues)
+	}
+}
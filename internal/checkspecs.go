@@ -2,25 +2,40 @@ package chkok
 
 import (
 	"fmt"
+	"maps"
+	"net"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
 // CheckSuitesFromSpecSuites creates checksuites from config check spec suites
 func CheckSuitesFromSpecSuites(specSuites ConfCheckSpecSuites) (CheckSuites, error) {
-	var checkSuites CheckSuites = make(map[string][]Check)
+	var checkSuites CheckSuites = make(map[string]CheckSuite)
 	var check Check
 	var err error
 	var spec ConfCheckSpec
-	for suite, specs := range specSuites {
-		checkSuites[suite] = []Check{}
-		for index := range specs {
-			spec = specs[index] // fixing G601: Implicit memory aliasing in for loop
+	for name, specSuite := range specSuites {
+		suite := CheckSuite{Checks: []Check{}, StopOnFailure: specSuite.StopOnFailure}
+		for index := range specSuite.Checks {
+			spec = specSuite.Checks[index] // fixing G601: Implicit memory aliasing in for loop
 			check, err = CheckFromSpec(&spec)
 			if err != nil {
 				return checkSuites, err
 			}
-			checkSuites[suite] = append(checkSuites[suite], check)
+			if hooks, ok := check.(checkHooks); ok {
+				hooks.setSuite(name)
+				severity, severityErr := ParseSeverity(spec.Severity)
+				if severityErr != nil {
+					return checkSuites, fmt.Errorf("check %v in suite %v: %w", check.Name(), name, severityErr)
+				}
+				hooks.setSeverity(severity)
+			}
+			suite.Checks = append(suite.Checks, check)
 		}
+		checkSuites[name] = suite
 	}
 	return checkSuites, err
 }
@@ -34,6 +49,26 @@ func CheckFromSpec(spec *ConfCheckSpec) (Check, error) {
 		check, err = CheckFileFromSpec(spec)
 	case "dial":
 		check, err = CheckDialFromSpec(spec)
+	case "http":
+		check, err = CheckHTTPFromSpec(spec)
+	case "tls":
+		check, err = CheckTLSFromSpec(spec)
+	case "cert_file":
+		check, err = CheckCertFileFromSpec(spec)
+	case "disk":
+		check, err = CheckDiskFromSpec(spec)
+	case "process":
+		check, err = CheckProcessFromSpec(spec)
+	case "pidfile":
+		check, err = CheckPIDFileFromSpec(spec)
+	case "systemd":
+		check, err = CheckSystemdFromSpec(spec)
+	case "dns":
+		check, err = CheckDNSFromSpec(spec)
+	case "listen":
+		check, err = CheckListenFromSpec(spec)
+	case "exec":
+		check, err = CheckExecFromSpec(spec)
 	default:
 		check = NewCheckFile("/")
 		err = fmt.Errorf("invalid check type '%v'", checkType)
@@ -61,6 +96,9 @@ func CheckFileFromSpec(spec *ConfCheckSpec) (*CheckFile, error) {
 	if spec.MaxSize != nil {
 		check.maxSize = *spec.MaxSize
 	}
+	if err = setCheckFileModes(check, spec); err != nil {
+		return check, err
+	}
 	if spec.User != nil {
 		id, err = getUID(*spec.User)
 		if err == nil {
@@ -81,22 +119,459 @@ func CheckFileFromSpec(spec *ConfCheckSpec) (*CheckFile, error) {
 	if spec.MaxFileCount != nil {
 		check.maxFileCount = *spec.MaxFileCount
 	}
+	if spec.WarnMinSize != nil {
+		check.warnMinSize = *spec.WarnMinSize
+	}
+	if spec.WarnMaxSize != nil {
+		check.warnMaxSize = *spec.WarnMaxSize
+	}
+	if spec.WarnMinFileCount != nil {
+		check.warnMinFileCount = *spec.WarnMinFileCount
+	}
+	if spec.WarnMaxFileCount != nil {
+		check.warnMaxFileCount = *spec.WarnMaxFileCount
+	}
+	if contentErr := setCheckFileContent(check, spec); contentErr != nil {
+		return check, contentErr
+	}
+	if ageErr := setCheckFileAge(check, spec); ageErr != nil {
+		return check, ageErr
+	}
+	if scanErr := setCheckFileDirScan(check, spec); scanErr != nil {
+		return check, scanErr
+	}
 
 	return check, err
 }
 
+// setCheckFileModes sets the permission bits the CheckFile expects from the ConfCheckSpec
+func setCheckFileModes(check *CheckFile, spec *ConfCheckSpec) error {
+	modes := []struct {
+		name  string
+		value *ConfFileMode
+		dest  *int32
+	}{
+		{"mode", spec.Mode, &check.mode},
+		{"min_mode", spec.MinMode, &check.minMode},
+		{"max_mode", spec.MaxMode, &check.maxMode},
+	}
+	for _, mode := range modes {
+		if mode.value == nil {
+			continue
+		}
+		if *mode.value > modeBitsMask {
+			return fmt.Errorf("file check %v '%o' is invalid", mode.name, *mode.value)
+		}
+		*mode.dest = int32(*mode.value) //nolint: gosec
+	}
+	return nil
+}
+
+// setCheckFileContent sets the file content and digest expectations of the check from the spec
+func setCheckFileContent(check *CheckFile, spec *ConfCheckSpec) error {
+	var err error
+	check.contains = spec.Contains
+	check.notContains = spec.NotContains
+	if spec.Regex != "" {
+		if check.regex, err = regexp.Compile(spec.Regex); err != nil {
+			return fmt.Errorf("file check %v regex is invalid: %v", spec.Path, err)
+		}
+	}
+	for _, digest := range []FileDigest{{"md5", spec.MD5}, {"sha256", spec.SHA256}, {"sha512", spec.SHA512}} {
+		if digest.Digest == "" {
+			continue
+		}
+		fileDigest, err := NewFileDigest(digest.Algorithm, digest.Digest)
+		if err != nil {
+			return fmt.Errorf("file check %v %v", spec.Path, err)
+		}
+		check.digests = append(check.digests, fileDigest)
+	}
+	check.checksumFile = spec.ChecksumFile
+	if spec.MaxReadSize != "" {
+		size, err := ParseByteSize(spec.MaxReadSize)
+		if err != nil || size == 0 {
+			return fmt.Errorf("file check %v max read size is invalid: %v", spec.Path, err)
+		}
+		check.maxReadSize = int64(size) //nolint: gosec
+	}
+	return nil
+}
+
+// setCheckFileAge sets the file age expectations of the check from the spec
+func setCheckFileAge(check *CheckFile, spec *ConfCheckSpec) error {
+	var err error
+	if check.ageOf, err = ParseFileTime(spec.AgeOf); err != nil {
+		return fmt.Errorf("file check %v age of is invalid: %v", spec.Path, err)
+	}
+	if spec.MinAge < 0 || spec.MaxAge < 0 || spec.NewestMaxAge < 0 {
+		return fmt.Errorf("file check %v ages should not be negative", spec.Path)
+	}
+	if spec.NewestMaxAge > 0 && check.fileType != TypeDir {
+		return fmt.Errorf("file check %v newest max age is only supported for dir checks", spec.Path)
+	}
+	check.minAge = spec.MinAge
+	check.maxAge = spec.MaxAge
+	check.newestMaxAge = spec.NewestMaxAge
+	return nil
+}
+
+// setCheckFileDirScan sets the directory scan options and total size limit of the check from the spec
+func setCheckFileDirScan(check *CheckFile, spec *ConfCheckSpec) error {
+	check.recursive = spec.Recursive
+	if spec.MaxDepth < 0 {
+		return fmt.Errorf("file check %v max depth should not be negative", spec.Path)
+	}
+	check.maxDepth = spec.MaxDepth
+	if _, err := filepath.Match(spec.Include, ""); err != nil {
+		return fmt.Errorf("file check %v include pattern is invalid: %v", spec.Path, err)
+	}
+	check.include = spec.Include
+	if spec.MaxTotalSize != "" {
+		size, err := ParseByteSize(spec.MaxTotalSize)
+		if err != nil {
+			return fmt.Errorf("file check %v max total size is invalid: %v", spec.Path, err)
+		}
+		check.maxTotalSize = int64(size) //nolint: gosec
+	}
+	check.timeout = spec.Timeout
+	if check.timeout == 0 && check.recursive {
+		check.timeout = DefaultDirScanTimeout
+	}
+	return nil
+}
+
 // CheckDialFromSpec creates a CheckDial from a ConfCheckSpec
 func CheckDialFromSpec(spec *ConfCheckSpec) (*CheckDial, error) {
 	var err error
 	check := NewCheckDial()
 	check.Absent = spec.Absent
 	switch network := strings.ToLower(spec.Network); network {
-	case "tcp":
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
 		check.Network = network
 	default:
 		err = fmt.Errorf("dial check network '%v' is not supported", spec.Network)
 	}
 	check.Address = spec.Address
-	check.timeout = spec.Timeout
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	if err == nil {
+		err = setCheckDialExchange(check, spec)
+	}
 	return check, err
 }
+
+// setCheckDialExchange sets the payload and the reply expectations of the dial check from the spec
+func setCheckDialExchange(check *CheckDial, spec *ConfCheckSpec) error {
+	send, err := unescapeString(spec.Send)
+	if err != nil {
+		return fmt.Errorf("dial check send is invalid: %w", err)
+	}
+	check.Send = []byte(send)
+	if spec.Expect != "" {
+		expect, err := unescapeString(spec.Expect)
+		if err != nil {
+			return fmt.Errorf("dial check expect is invalid: %w", err)
+		}
+		check.Expect = []byte(expect)
+	}
+	if spec.ExpectRegex != "" {
+		if check.ExpectRegex, err = regexp.Compile(spec.ExpectRegex); err != nil {
+			return fmt.Errorf("dial check expect_regex is invalid: %w", err)
+		}
+	}
+	return nil
+}
+
+// unescapeString returns the value with the Go escape sequences (e.g. \r\n, \x00) replaced
+func unescapeString(value string) (string, error) {
+	var builder strings.Builder
+	for value != "" {
+		char, multibyte, tail, err := strconv.UnquoteChar(value, 0)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q: %w", value, err)
+		}
+		if multibyte {
+			builder.WriteRune(char)
+		} else {
+			builder.WriteByte(byte(char)) //nolint: gosec
+		}
+		value = tail
+	}
+	return builder.String(), nil
+}
+
+// CheckHTTPFromSpec creates a CheckHTTP from a ConfCheckSpec
+func CheckHTTPFromSpec(spec *ConfCheckSpec) (*CheckHTTP, error) {
+	var err error
+	check := NewCheckHTTP()
+	check.URL = spec.URL
+	if spec.URL == "" {
+		return check, fmt.Errorf("http check url is empty")
+	}
+	if spec.Method != "" {
+		check.Method = strings.ToUpper(spec.Method)
+	}
+	if len(spec.StatusCodes) > 0 {
+		check.StatusCodes = []StatusCodeRange{}
+		for _, value := range spec.StatusCodes {
+			codeRange, err := ParseStatusCodeRange(value)
+			if err != nil {
+				return check, fmt.Errorf("http check %v: %v", spec.URL, err)
+			}
+			check.StatusCodes = append(check.StatusCodes, codeRange)
+		}
+	}
+	maps.Copy(check.Headers, spec.Headers)
+	check.BodyContains = spec.BodyContains
+	if spec.BodyRegex != "" {
+		if check.BodyRegex, err = regexp.Compile(spec.BodyRegex); err != nil {
+			return check, fmt.Errorf("http check %v body regex is invalid: %v", spec.URL, err)
+		}
+	}
+	check.MaxLatency = spec.MaxLatency
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	return check, err
+}
+
+// CheckTLSFromSpec creates a CheckTLS from a ConfCheckSpec
+func CheckTLSFromSpec(spec *ConfCheckSpec) (*CheckTLS, error) {
+	var err error
+	check := NewCheckTLS()
+	if spec.Network != "" && strings.ToLower(spec.Network) != "tcp" {
+		return check, fmt.Errorf("tls check network '%v' is not supported", spec.Network)
+	}
+	if spec.Address == "" {
+		return check, fmt.Errorf("tls check address is empty")
+	}
+	check.Address = spec.Address
+	check.ServerName = spec.ServerName
+	if spec.CAFile != "" {
+		if check.RootCAs, err = loadCertPool(spec.CAFile); err != nil {
+			return check, fmt.Errorf("tls check %v ca file is invalid: %v", spec.Address, err)
+		}
+	}
+	check.MinValidFor = spec.MinValidFor
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	return check, err
+}
+
+// CheckCertFileFromSpec creates a CheckCertFile from a ConfCheckSpec
+func CheckCertFileFromSpec(spec *ConfCheckSpec) (*CheckCertFile, error) {
+	fileCheck, err := CheckFileFromSpec(spec)
+	check := NewCheckCertFile(spec.Path)
+	check.CheckFile = *fileCheck
+	check.fileType = TypeFile
+	check.MinValidFor = spec.MinValidFor
+	check.Subject = spec.Subject
+	check.Issuer = spec.Issuer
+	check.SANs = spec.SANs
+	check.KeyFile = spec.KeyFile
+	return check, err
+}
+
+// CheckDiskFromSpec creates a CheckDisk from a ConfCheckSpec
+func CheckDiskFromSpec(spec *ConfCheckSpec) (*CheckDisk, error) {
+	var err error
+	check := NewCheckDisk(spec.Path)
+	if spec.Path == "" {
+		return check, fmt.Errorf("disk check path is empty")
+	}
+	if spec.MinFree != "" {
+		if check.MinFree, err = ParseByteSize(spec.MinFree); err != nil {
+			return check, fmt.Errorf("disk check %v min free is invalid: %v", spec.Path, err)
+		}
+	}
+	if spec.MinFreePercent < 0 || spec.MinFreePercent > 100 || spec.MinFreeInodesPercent < 0 ||
+		spec.MinFreeInodesPercent > 100 {
+		return check, fmt.Errorf("disk check %v min free percentages should be between 0 and 100", spec.Path)
+	}
+	check.MinFreePercent = spec.MinFreePercent
+	check.MinFreeInodesPercent = spec.MinFreeInodesPercent
+	return check, err
+}
+
+// CheckProcessFromSpec creates a CheckProcess from a ConfCheckSpec
+func CheckProcessFromSpec(spec *ConfCheckSpec) (*CheckProcess, error) {
+	var err error
+	check := NewCheckProcess(spec.Name)
+	if spec.Name == "" && spec.CmdlineRegex == "" && spec.PIDFile == "" {
+		return check, fmt.Errorf("process check needs a name, cmdline_regex or pid_file")
+	}
+	if spec.CmdlineRegex != "" {
+		if check.CmdlineRegex, err = regexp.Compile(spec.CmdlineRegex); err != nil {
+			return check, fmt.Errorf("process check cmdline regex is invalid: %v", err)
+		}
+	}
+	check.PIDFile = spec.PIDFile
+	if spec.MinCount != nil {
+		check.MinCount = *spec.MinCount
+	}
+	if spec.MaxCount != nil {
+		check.MaxCount = *spec.MaxCount
+	}
+	if spec.User != nil {
+		if check.uid, err = getUID(*spec.User); err != nil {
+			return check, fmt.Errorf("process check user %v is invalid: %v", *spec.User, err)
+		}
+	}
+	if spec.MaxRSS != "" {
+		if check.MaxRSS, err = ParseByteSize(spec.MaxRSS); err != nil {
+			return check, fmt.Errorf("process check max rss is invalid: %v", err)
+		}
+	}
+	check.MaxCPUTime = spec.MaxCPUTime
+	return check, err
+}
+
+// CheckPIDFileFromSpec creates a CheckPIDFile from a ConfCheckSpec
+func CheckPIDFileFromSpec(spec *ConfCheckSpec) (*CheckPIDFile, error) {
+	fileCheck, err := CheckFileFromSpec(spec)
+	check := NewCheckPIDFile(spec.Path)
+	check.CheckFile = *fileCheck
+	check.fileType = TypeFile
+	check.ProcessName = spec.Name
+	return check, err
+}
+
+// CheckSystemdFromSpec creates a CheckSystemd from a ConfCheckSpec
+func CheckSystemdFromSpec(spec *ConfCheckSpec) (*CheckSystemd, error) {
+	var err error
+	check := NewCheckSystemd(spec.Unit)
+	if spec.Unit == "" {
+		return check, fmt.Errorf("systemd check unit is empty")
+	}
+	if spec.ActiveState != "" {
+		check.ActiveState = spec.ActiveState
+	}
+	check.SubState = spec.SubState
+	check.Enabled = spec.Enabled
+	if spec.MaxRestarts != nil {
+		check.MaxRestarts = *spec.MaxRestarts
+	}
+	if spec.Systemctl != "" {
+		check.Systemctl = spec.Systemctl
+	}
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	return check, err
+}
+
+// CheckDNSFromSpec creates a CheckDNS from a ConfCheckSpec
+func CheckDNSFromSpec(spec *ConfCheckSpec) (*CheckDNS, error) {
+	check := NewCheckDNS(spec.Name)
+	if spec.Name == "" {
+		return check, fmt.Errorf("dns check name is empty")
+	}
+	if spec.RecordType != "" {
+		check.RecordType = strings.ToUpper(spec.RecordType)
+	}
+	if !slices.Contains(DNSRecordTypes, check.RecordType) {
+		return check, fmt.Errorf("dns check record type %q is not supported", spec.RecordType)
+	}
+	check.Server = spec.Server
+	if _, _, err := net.SplitHostPort(check.Server); check.Server != "" && err != nil {
+		check.Server = net.JoinHostPort(check.Server, "53")
+	}
+	check.Answers = spec.Answers
+	if spec.MinCount != nil {
+		check.MinCount = *spec.MinCount
+	}
+	check.Absent = spec.Absent
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	return check, nil
+}
+
+// CheckListenFromSpec creates a CheckListen from a ConfCheckSpec
+func CheckListenFromSpec(spec *ConfCheckSpec) (*CheckListen, error) {
+	var err error
+	check := NewCheckListen(spec.Port)
+	switch network := strings.ToLower(spec.Network); network {
+	case "", "tcp":
+	case "udp":
+		check.Network = network
+	case "unix":
+		check.Network = network
+		if spec.Path == "" {
+			return check, fmt.Errorf("listen check unix socket path is empty")
+		}
+		check.Path = spec.Path
+	default:
+		return check, fmt.Errorf("listen check network '%v' is not supported", spec.Network)
+	}
+	if check.Network != "unix" && (spec.Port < 1 || spec.Port > 65535) {
+		return check, fmt.Errorf("listen check port %v is invalid", spec.Port)
+	}
+	if spec.Address != "" {
+		if check.Address = net.ParseIP(spec.Address); check.Address == nil {
+			return check, fmt.Errorf("listen check address %v is not an IP", spec.Address)
+		}
+	}
+	check.NoWildcard = spec.NoWildcard
+	check.ProcessName = spec.Name
+	check.Absent = spec.Absent
+	if spec.User != nil {
+		if check.uid, err = getUID(*spec.User); err != nil {
+			return check, fmt.Errorf("listen check user %v is invalid: %v", *spec.User, err)
+		}
+	}
+	return check, err
+}
+
+// CheckExecFromSpec creates a CheckExec from a ConfCheckSpec
+func CheckExecFromSpec(spec *ConfCheckSpec) (*CheckExec, error) {
+	var err error
+	check := NewCheckExec(spec.Command...)
+	if len(spec.Command) == 0 || spec.Command[0] == "" {
+		return check, fmt.Errorf("exec check command is empty")
+	}
+	for _, name := range slices.Sorted(maps.Keys(spec.Env)) {
+		check.Env = append(check.Env, name+"="+spec.Env[name])
+	}
+	check.Dir = spec.Dir
+	if spec.ExitCode != nil {
+		check.ExitCode = *spec.ExitCode
+	}
+	check.StdoutContains = spec.StdoutContains
+	check.StderrContains = spec.StderrContains
+	if spec.StdoutRegex != "" {
+		if check.StdoutRegex, err = regexp.Compile(spec.StdoutRegex); err != nil {
+			return check, fmt.Errorf("exec check stdout regex is invalid: %v", err)
+		}
+	}
+	if spec.StderrRegex != "" {
+		if check.StderrRegex, err = regexp.Compile(spec.StderrRegex); err != nil {
+			return check, fmt.Errorf("exec check stderr regex is invalid: %v", err)
+		}
+	}
+	if spec.MaxReadSize != "" {
+		size, err := ParseByteSize(spec.MaxReadSize)
+		if err != nil || size == 0 {
+			return check, fmt.Errorf("exec check max read size is invalid: %v", err)
+		}
+		check.MaxReadSize = int(size) //nolint: gosec
+	}
+	if spec.Timeout > 0 {
+		check.timeout = spec.Timeout
+	}
+	return check, err
+}
+
+// ParseSeverity returns the Severity of the name, "critical" (default if empty) or "warning"
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "", "critical":
+		return SeverityCritical, nil
+	case "warning":
+		return SeverityWarning, nil
+	}
+	return SeverityCritical, fmt.Errorf("invalid severity %q, want critical or warning", name)
+}
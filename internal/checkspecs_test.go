@@ -0,0 +1,440 @@
+package chkok
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckFileFromSpecModes(t *testing.T) {
+	var mode, minMode, maxMode ConfFileMode = 0o755, 0o700, 0o4755
+	spec := ConfCheckSpec{Type: "file", Path: "../LICENSE", Mode: &mode, MinMode: &minMode, MaxMode: &maxMode}
+	check, err := CheckFileFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check file from spec want no err, got %v", err)
+	}
+	if check.mode != 0o755 || check.minMode != 0o700 || check.maxMode != 0o4755 {
+		t.Errorf("check file from spec want modes 0755/0700/4755, got %o/%o/%o", check.mode, check.minMode, check.maxMode)
+	}
+
+	var invalidMode ConfFileMode = 0o17777
+	spec = ConfCheckSpec{Type: "file", Path: "../LICENSE", Mode: &invalidMode}
+	if _, err = CheckFileFromSpec(&spec); err == nil {
+		t.Errorf("check file from spec with invalid mode want err, got nil")
+	}
+}
+
+func TestCheckFileFromSpecContent(t *testing.T) {
+	spec := ConfCheckSpec{Type: "file", Path: "../LICENSE", Contains: []string{"Copyright"},
+		NotContains: []string{"GPL"}, Regex: `(?i)permission is hereby granted`, MaxReadSize: "64KiB"}
+	check, err := CheckFileFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check file from spec want no err, got %v", err)
+	}
+	if len(check.contains) != 1 || len(check.notContains) != 1 || check.regex == nil || check.maxReadSize != 64<<10 {
+		t.Errorf("check file from spec want content expectations set, got %+v", check)
+	}
+
+	spec = ConfCheckSpec{Type: "file", Path: "../LICENSE", MD5: "45868b2390fc6e14e6c8f4d5b1bf1b04",
+		SHA256: "3E52AF6059683B3AB1B15709AAA66213DDF1AACAFA27E764AAA9E83A0AE2B1F2", ChecksumFile: "/etc/SHA256SUMS"}
+	if check, err = CheckFileFromSpec(&spec); err != nil {
+		t.Fatalf("check file from spec want no err, got %v", err)
+	}
+	if len(check.digests) != 2 || check.digests[1].Algorithm != "sha256" || check.checksumFile != "/etc/SHA256SUMS" {
+		t.Errorf("check file from spec want digests and checksum file set, got %+v", check)
+	}
+
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "file", Path: "../LICENSE", Regex: "("},
+		{Type: "file", Path: "../LICENSE", MaxReadSize: "0"},
+		{Type: "file", Path: "../LICENSE", SHA256: "45868b2390fc6e14e6c8f4d5b1bf1b04"},
+	}
+	for _, spec := range invalidSpecs {
+		if _, err = CheckFileFromSpec(&spec); err == nil {
+			t.Errorf("check file from spec %+v want err, got nil", spec)
+		}
+	}
+}
+
+func TestCheckFileFromSpecAge(t *testing.T) {
+	spec := ConfCheckSpec{Type: "dir", Path: "../cmd", AgeOf: "ctime", MinAge: time.Minute, MaxAge: 26 * time.Hour,
+		NewestMaxAge: time.Hour}
+	check, err := CheckFileFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check file from spec want no err, got %v", err)
+	}
+	if check.ageOf != FileTimeChange || check.minAge != time.Minute || check.maxAge != 26*time.Hour ||
+		check.newestMaxAge != time.Hour {
+		t.Errorf("check file from spec want age expectations set, got %+v", check)
+	}
+
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "file", Path: "../LICENSE", AgeOf: "btime"},
+		{Type: "file", Path: "../LICENSE", MaxAge: -time.Hour},
+		{Type: "file", Path: "../LICENSE", NewestMaxAge: time.Hour},
+	}
+	for _, spec := range invalidSpecs {
+		if _, err = CheckFileFromSpec(&spec); err == nil {
+			t.Errorf("check file from spec %+v want err, got nil", spec)
+		}
+	}
+}
+
+func TestCheckFileFromSpecDirScan(t *testing.T) {
+	spec := ConfCheckSpec{Type: "dir", Path: "../cmd", Recursive: true, MaxDepth: 3, Include: "*.go",
+		MaxTotalSize: "10MB", Timeout: time.Second}
+	check, err := CheckFileFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check file from spec want no err, got %v", err)
+	}
+	if !check.recursive || check.maxDepth != 3 || check.include != "*.go" || check.maxTotalSize != 10000000 ||
+		check.GetTimeout() != time.Second {
+		t.Errorf("check file from spec want dir scan options set, got %+v", check)
+	}
+
+	spec = ConfCheckSpec{Type: "dir", Path: "../cmd", MaxTotalSize: "10MB"}
+	if check, err = CheckFileFromSpec(&spec); err != nil || check.GetTimeout() != 0 {
+		t.Errorf("check file from spec want no timeout by default, got %v %v", check.GetTimeout(), err)
+	}
+	spec.Recursive = true
+	if check, err = CheckFileFromSpec(&spec); err != nil || check.GetTimeout() != DefaultDirScanTimeout {
+		t.Errorf("check file from spec want default timeout of recursive scans, got %v %v", check.GetTimeout(), err)
+	}
+
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "dir", Path: "../cmd", Include: "[*.go"},
+		{Type: "dir", Path: "../cmd", MaxTotalSize: "-1"},
+		{Type: "dir", Path: "../cmd", MaxDepth: -1},
+	}
+	for _, spec := range invalidSpecs {
+		if _, err = CheckFileFromSpec(&spec); err == nil {
+			t.Errorf("check file from spec %+v want err, got nil", spec)
+		}
+	}
+}
+
+func TestCheckHTTPFromSpec(t *testing.T) {
+	spec := ConfCheckSpec{
+		Type:        "http",
+		URL:         "http://127.0.0.1:8080/health",
+		Method:      "head",
+		StatusCodes: []string{"2xx", "301"},
+		Headers:     map[string]string{"Content-Type": "application/json"},
+		BodyRegex:   "^ok$",
+		Timeout:     2 * time.Second,
+	}
+	check, err := CheckHTTPFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check http from spec want no err, got %v", err)
+	}
+	if check.Method != "HEAD" {
+		t.Errorf("check http from spec want method HEAD, got %v", check.Method)
+	}
+	if len(check.StatusCodes) != 2 || check.StatusCodes[1].Min != 301 {
+		t.Errorf("check http from spec want status codes 2xx and 301, got %v", check.StatusCodes)
+	}
+	if check.GetTimeout() != 2*time.Second {
+		t.Errorf("check http from spec want timeout 2s, got %v", check.GetTimeout())
+	}
+
+	spec.BodyRegex = "(invalid"
+	if _, err = CheckHTTPFromSpec(&spec); err == nil {
+		t.Errorf("check http from spec with invalid regex want err, got nil")
+	}
+	spec.BodyRegex = ""
+	spec.StatusCodes = []string{"ok"}
+	if _, err = CheckHTTPFromSpec(&spec); err == nil {
+		t.Errorf("check http from spec with invalid status codes want err, got nil")
+	}
+}
+
+func TestCheckTLSFromSpec(t *testing.T) {
+	server, caFile := newTestTLSServer(t)
+	defer server.Close()
+
+	spec := ConfCheckSpec{Type: "tls", Address: server.Listener.Addr().String(), CAFile: caFile,
+		ServerName: testTLSServerName, MinValidFor: 24 * time.Hour, Timeout: 2 * time.Second}
+	check, err := CheckTLSFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check tls from spec want no err, got %v", err)
+	}
+	if result := check.Run(); !result.IsOK {
+		t.Errorf("check tls from spec want ok, got issues %v", result.Issues)
+	}
+
+	spec.CAFile = "../LICENSE"
+	if _, err = CheckTLSFromSpec(&spec); err == nil {
+		t.Errorf("check tls from spec with invalid ca file want err, got nil")
+	}
+}
+
+func TestCheckCertFileFromSpec(t *testing.T) {
+	certPath, keyPath := writeTestCertFile(t, t.TempDir(), 30*24*time.Hour)
+	user := "root"
+	spec := ConfCheckSpec{Type: "cert_file", Path: certPath, User: &user, KeyFile: keyPath,
+		SANs: []string{"chkok.example.com"}, MinValidFor: 24 * time.Hour}
+	check, err := CheckCertFileFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check cert file from spec want no err, got %v", err)
+	}
+	if check.uid != 0 || check.fileType != TypeFile {
+		t.Errorf("check cert file from spec want root owned regular file, got uid %v type %v", check.uid, check.fileType)
+	}
+	if check.KeyFile != keyPath || check.MinValidFor != 24*time.Hour || len(check.SANs) != 1 {
+		t.Errorf("check cert file from spec want key file, min valid for and SANs set, got %+v", check)
+	}
+}
+
+func TestCheckDialFromSpec(t *testing.T) {
+	spec := ConfCheckSpec{Type: "dial", Network: "UDP", Address: "127.0.0.1:53", Send: "ping"}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check dial from spec want no err, got %v", err)
+	}
+	dial, ok := check.(*CheckDial)
+	if !ok || dial.Network != "udp" || string(dial.Send) != "ping" || dial.GetTimeout() != DefaultDialTimeout {
+		t.Errorf("check dial from spec want udp network and payload set, got %+v", check)
+	}
+	for _, network := range []string{"tcp4", "tcp6", "unix", "unixgram"} {
+		if dial, err = CheckDialFromSpec(&ConfCheckSpec{Type: "dial", Network: network}); err != nil {
+			t.Errorf("check dial from spec with network %v want no err, got %v", network, err)
+		}
+		if dial.GetTimeout() != DefaultDialTimeout {
+			t.Errorf("check dial from spec without timeout want %v, got %v", DefaultDialTimeout, dial.GetTimeout())
+		}
+	}
+	if _, err = CheckDialFromSpec(&ConfCheckSpec{Type: "dial", Network: "ip4"}); err == nil {
+		t.Errorf("check dial from spec with unsupported network want err, got nil")
+	}
+
+	spec = ConfCheckSpec{Type: "dial", Network: "tcp", Address: "127.0.0.1:6379", Send: `PING\r\n`,
+		Expect: `+PONG\x0d\n`, ExpectRegex: `^\+PONG`}
+	if dial, err = CheckDialFromSpec(&spec); err != nil {
+		t.Fatalf("check dial from spec with send and expect want no err, got %v", err)
+	}
+	if string(dial.Send) != "PING\r\n" || string(dial.Expect) != "+PONG\r\n" || dial.ExpectRegex.String() != `^\+PONG` {
+		t.Errorf("check dial from spec want escaped send and expectations set, got %+v", dial)
+	}
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "dial", Network: "tcp", Send: `\q`},
+		{Type: "dial", Network: "tcp", Expect: `trailing \`},
+		{Type: "dial", Network: "tcp", ExpectRegex: "["},
+	}
+	for _, invalid := range invalidSpecs {
+		if _, err = CheckDialFromSpec(&invalid); err == nil {
+			t.Errorf("check dial from spec want err for invalid spec %+v, got nil", invalid)
+		}
+	}
+}
+
+func TestCheckDNSFromSpec(t *testing.T) {
+	minCount := 2
+	spec := ConfCheckSpec{Type: "dns", Name: "app.example.com", RecordType: "aaaa", Server: "10.0.0.53",
+		Answers: []string{"2001:db8::1"}, MinCount: &minCount, Timeout: time.Second}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check dns from spec want no err, got %v", err)
+	}
+	dns, ok := check.(*CheckDNS)
+	if !ok || dns.Host != "app.example.com" || dns.RecordType != "AAAA" || dns.Server != "10.0.0.53:53" ||
+		len(dns.Answers) != 1 || dns.MinCount != 2 || dns.GetTimeout() != time.Second {
+		t.Errorf("check dns from spec want name, record type and answers set, got %+v", check)
+	}
+	spec = ConfCheckSpec{Type: "dns", Name: "old.example.com", Server: "[2001:db8::53]:5353", Absent: true}
+	if dns, err = CheckDNSFromSpec(&spec); err != nil || dns.Server != "[2001:db8::53]:5353" || !dns.Absent {
+		t.Errorf("check dns from spec want absent with server port kept, got %+v, err %v", dns, err)
+	}
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "dns"},
+		{Type: "dns", Name: "app.example.com", RecordType: "PTR"},
+	}
+	for _, invalid := range invalidSpecs {
+		if _, err = CheckDNSFromSpec(&invalid); err == nil {
+			t.Errorf("check dns from spec want err for invalid spec %+v, got nil", invalid)
+		}
+	}
+}
+
+func TestCheckListenFromSpec(t *testing.T) {
+	user := "root"
+	spec := ConfCheckSpec{Type: "listen", Port: 5432, Address: "127.0.0.1", NoWildcard: true, Name: "postgres",
+		User: &user}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check listen from spec want no err, got %v", err)
+	}
+	listen, ok := check.(*CheckListen)
+	if !ok || listen.Network != "tcp" || listen.Port != 5432 || !listen.Address.Equal(net.IPv4(127, 0, 0, 1)) ||
+		!listen.NoWildcard || listen.ProcessName != "postgres" || listen.uid != 0 || listen.procRoot != "/proc" {
+		t.Errorf("check listen from spec want port, address and owner set, got %+v", check)
+	}
+	spec = ConfCheckSpec{Type: "listen", Network: "unix", Path: "/run/app.sock", Absent: true}
+	if listen, err = CheckListenFromSpec(&spec); err != nil || listen.Path != "/run/app.sock" || !listen.Absent {
+		t.Errorf("check listen from spec want absent unix socket, got %+v, err %v", listen, err)
+	}
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "listen"},
+		{Type: "listen", Network: "udp", Port: 70000},
+		{Type: "listen", Network: "unix"},
+		{Type: "listen", Network: "sctp", Port: 22},
+		{Type: "listen", Port: 22, Address: "localhost"},
+	}
+	for _, invalid := range invalidSpecs {
+		if _, err = CheckListenFromSpec(&invalid); err == nil {
+			t.Errorf("check listen from spec want err for invalid spec %+v, got nil", invalid)
+		}
+	}
+}
+
+func TestCheckExecFromSpec(t *testing.T) {
+	exitCode := 2
+	spec := ConfCheckSpec{Type: "exec", Command: []string{"/usr/local/bin/check-queue", "--max", "10"},
+		Env: map[string]string{"QUEUE": "jobs", "LANG": "C"}, Dir: "/tmp", ExitCode: &exitCode,
+		StdoutContains: "ok", StdoutRegex: `^\d+ jobs`, StderrRegex: "^$", MaxReadSize: "64KiB", Timeout: time.Second}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check exec from spec want no err, got %v", err)
+	}
+	exec, ok := check.(*CheckExec)
+	if !ok || len(exec.Command) != 3 || strings.Join(exec.Env, ",") != "LANG=C,QUEUE=jobs" || exec.Dir != "/tmp" ||
+		exec.ExitCode != 2 || exec.StdoutContains != "ok" || exec.StdoutRegex == nil || exec.StderrRegex == nil ||
+		exec.MaxReadSize != 64*1024 || exec.GetTimeout() != time.Second {
+		t.Errorf("check exec from spec want command, environment and expectations set, got %+v", check)
+	}
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "exec"},
+		{Type: "exec", Command: []string{"true"}, StdoutRegex: "["},
+		{Type: "exec", Command: []string{"true"}, StderrRegex: "["},
+		{Type: "exec", Command: []string{"true"}, MaxReadSize: "lots"},
+	}
+	for _, invalid := range invalidSpecs {
+		if _, err = CheckExecFromSpec(&invalid); err == nil {
+			t.Errorf("check exec from spec want err for invalid spec %+v, got nil", invalid)
+		}
+	}
+}
+
+func TestCheckSuitesFromSpecSuites(t *testing.T) {
+	specSuites := ConfCheckSpecSuites{
+		"files": ConfCheckSpecSuite{
+			Checks:        []ConfCheckSpec{{Type: "file", Path: "../LICENSE"}, {Type: "dir", Path: "../cmd"}},
+			StopOnFailure: true,
+		},
+	}
+	suites, err := CheckSuitesFromSpecSuites(specSuites)
+	if err != nil {
+		t.Fatalf("check suites from spec suites want no err, got %v", err)
+	}
+	suite, ok := suites["files"]
+	if !ok || len(suite.Checks) != 2 || !suite.StopOnFailure {
+		t.Fatalf("check suites from spec suites want files suite with 2 checks and stop on failure, got %+v", suite)
+	}
+	for _, chk := range suite.Checks {
+		if chk.Suite() != "files" {
+			t.Errorf("check suites from spec suites want check %v in files suite, got %v", chk.Name(), chk.Suite())
+		}
+	}
+
+	specSuites["invalid"] = ConfCheckSpecSuite{Checks: []ConfCheckSpec{{Type: "invalid"}}}
+	if _, err = CheckSuitesFromSpecSuites(specSuites); err == nil {
+		t.Errorf("check suites from spec suites with invalid check type want err, got nil")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	testCases := map[string]Severity{"": SeverityCritical, "critical": SeverityCritical, "Warning": SeverityWarning}
+	for name, want := range testCases {
+		if got, err := ParseSeverity(name); err != nil || got != want {
+			t.Errorf("parse severity %q want %v, got %v err %v", name, want, got, err)
+		}
+	}
+	if _, err := ParseSeverity("info"); err == nil {
+		t.Errorf("parse severity invalid want err, got nil")
+	}
+	specSuites := ConfCheckSpecSuites{
+		"files": ConfCheckSpecSuite{Checks: []ConfCheckSpec{{Type: "file", Path: "../LICENSE", Severity: "info"}}},
+	}
+	if _, err := CheckSuitesFromSpecSuites(specSuites); err == nil {
+		t.Errorf("check suites from spec suites with invalid severity want err, got nil")
+	}
+}
+
+func TestCheckDiskFromSpec(t *testing.T) {
+	spec := ConfCheckSpec{Type: "disk", Path: "/", MinFree: "5GiB", MinFreePercent: 10, MinFreeInodesPercent: 5}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check disk from spec want no err, got %v", err)
+	}
+	disk, ok := check.(*CheckDisk)
+	if !ok || disk.MinFree != 5<<30 || disk.MinFreePercent != 10 || disk.MinFreeInodesPercent != 5 {
+		t.Errorf("check disk from spec want min free thresholds set, got %+v", check)
+	}
+
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "disk"},
+		{Type: "disk", Path: "/", MinFree: "5XB"},
+		{Type: "disk", Path: "/", MinFreePercent: 110},
+	}
+	for _, spec := range invalidSpecs {
+		if _, err = CheckDiskFromSpec(&spec); err == nil {
+			t.Errorf("check disk from spec %+v want err, got nil", spec)
+		}
+	}
+}
+
+func TestCheckProcessFromSpec(t *testing.T) {
+	user, minCount, maxCount := "root", 1, 1
+	spec := ConfCheckSpec{Type: "process", Name: "worker", CmdlineRegex: "--queue=jobs", User: &user,
+		MinCount: &minCount, MaxCount: &maxCount, MaxRSS: "512MiB", MaxCPUTime: time.Hour}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check process from spec want no err, got %v", err)
+	}
+	process, ok := check.(*CheckProcess)
+	if !ok || process.uid != 0 || process.MaxCount != 1 || process.MaxRSS != 512<<20 || process.CmdlineRegex == nil {
+		t.Errorf("check process from spec want owner, counts, max rss and cmdline regex set, got %+v", check)
+	}
+
+	invalidSpecs := []ConfCheckSpec{
+		{Type: "process"},
+		{Type: "process", CmdlineRegex: "("},
+		{Type: "process", Name: "worker", MaxRSS: "lots"},
+	}
+	for _, spec := range invalidSpecs {
+		if _, err = CheckProcessFromSpec(&spec); err == nil {
+			t.Errorf("check process from spec %+v want err, got nil", spec)
+		}
+	}
+}
+
+func TestCheckPIDFileFromSpec(t *testing.T) {
+	user := "root"
+	spec := ConfCheckSpec{Type: "pidfile", Path: "/run/worker.pid", User: &user, Name: "worker"}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check pid file from spec want no err, got %v", err)
+	}
+	pidFile, ok := check.(*CheckPIDFile)
+	if !ok || pidFile.uid != 0 || pidFile.fileType != TypeFile || pidFile.ProcessName != "worker" {
+		t.Errorf("check pid file from spec want root owned regular file and process name, got %+v", check)
+	}
+}
+
+func TestCheckSystemdFromSpec(t *testing.T) {
+	enabled, maxRestarts := true, 3
+	spec := ConfCheckSpec{Type: "systemd", Unit: "nginx.service", SubState: "running", Enabled: &enabled,
+		MaxRestarts: &maxRestarts, Systemctl: "/bin/systemctl", Timeout: time.Second}
+	check, err := CheckFromSpec(&spec)
+	if err != nil {
+		t.Fatalf("check systemd from spec want no err, got %v", err)
+	}
+	systemd, ok := check.(*CheckSystemd)
+	if !ok || systemd.ActiveState != "active" || systemd.SubState != "running" || !*systemd.Enabled ||
+		systemd.MaxRestarts != 3 || systemd.Systemctl != "/bin/systemctl" || systemd.GetTimeout() != time.Second {
+		t.Errorf("check systemd from spec want unit state expectations set, got %+v", check)
+	}
+	if _, err = CheckSystemdFromSpec(&ConfCheckSpec{Type: "systemd"}); err == nil {
+		t.Errorf("check systemd from spec without unit want err, got nil")
+	}
+}
@@ -1,8 +1,12 @@
 package chkok
 
 import (
+	"fmt"
 	"maps"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -11,8 +15,15 @@ import (
 // ConfRunners is a map of runner name to its config ConfRunner
 type ConfRunners map[string]ConfRunner
 
-// ConfCheckSpecSuites is list of ConfCheckSpec grouped by name
-type ConfCheckSpecSuites map[string][]ConfCheckSpec
+// ConfCheckSpecSuites is map of suite name to its config ConfCheckSpecSuite
+type ConfCheckSpecSuites map[string]ConfCheckSpecSuite
+
+// ConfCheckSpecSuite is a list of ConfCheckSpec that run sequentially, with suite options.
+// In YAML it's either a list of check specs, or a mapping with "checks" and the options.
+type ConfCheckSpecSuite struct {
+	Checks        []ConfCheckSpec
+	StopOnFailure bool `yaml:"stop_on_failure"`
+}
 
 // Conf is app configurations struct
 type Conf struct {
@@ -35,23 +46,99 @@ type ConfRunner struct {
 	ResponseTimeout        *string           `yaml:"response_timeout"`
 	ResponseUnavailable    *string           `yaml:"response_unavailable"`
 	ResponseInvalidRequest *string           `yaml:"response_invalid_request"`
+	ResponseNotFound       *string           `yaml:"response_not_found"`
+	Output                 *string
+	Interval               *time.Duration
+	MaxResultAge           *time.Duration `yaml:"max_result_age"`
+	ResponseStale          *string        `yaml:"response_stale"`
+	ResponseWarning        *string        `yaml:"response_warning"`
+	ResponseWarningStatus  *int           `yaml:"response_warning_status"`
 }
 
 // ConfCheckSpec is the spec for each check configuration
 type ConfCheckSpec struct {
-	Type         string
-	Path         string
-	Mode         *uint32
-	User         *string
-	Group        *string
-	MinSize      int32  `yaml:"min_size"`
-	MaxSize      *int64 `yaml:"max_size"`
-	Absent       bool
-	Network      string
-	Address      string
-	Timeout      time.Duration
-	MinFileCount *int `yaml:"min_file_count"`
-	MaxFileCount *int `yaml:"max_file_count"`
+	Type                 string
+	Path                 string
+	Mode                 *ConfFileMode
+	MinMode              *ConfFileMode `yaml:"min_mode"`
+	MaxMode              *ConfFileMode `yaml:"max_mode"`
+	User                 *string
+	Group                *string
+	MinSize              int32  `yaml:"min_size"`
+	MaxSize              *int64 `yaml:"max_size"`
+	Absent               bool
+	Network              string
+	Address              string
+	Timeout              time.Duration
+	MinFileCount         *int `yaml:"min_file_count"`
+	MaxFileCount         *int `yaml:"max_file_count"`
+	URL                  string
+	Method               string
+	StatusCodes          []string `yaml:"status_codes"`
+	Headers              map[string]string
+	BodyContains         string        `yaml:"body_contains"`
+	BodyRegex            string        `yaml:"body_regex"`
+	MaxLatency           time.Duration `yaml:"max_latency"`
+	ServerName           string        `yaml:"server_name"`
+	CAFile               string        `yaml:"ca_file"`
+	MinValidFor          time.Duration `yaml:"min_valid_for"`
+	Subject              string
+	Issuer               string
+	SANs                 []string `yaml:"sans"`
+	KeyFile              string   `yaml:"key_file"`
+	Severity             string
+	WarnMinSize          *int64        `yaml:"warn_min_size"`
+	WarnMaxSize          *int64        `yaml:"warn_max_size"`
+	WarnMinFileCount     *int          `yaml:"warn_min_file_count"`
+	WarnMaxFileCount     *int          `yaml:"warn_max_file_count"`
+	MinFree              string        `yaml:"min_free"`
+	MinFreePercent       float64       `yaml:"min_free_percent"`
+	MinFreeInodesPercent float64       `yaml:"min_free_inodes_percent"`
+	Name                 string        `yaml:"name"`
+	CmdlineRegex         string        `yaml:"cmdline_regex"`
+	PIDFile              string        `yaml:"pid_file"`
+	MinCount             *int          `yaml:"min_count"`
+	MaxCount             *int          `yaml:"max_count"`
+	MaxRSS               string        `yaml:"max_rss"`
+	MaxCPUTime           time.Duration `yaml:"max_cpu_time"`
+	Unit                 string
+	ActiveState          string `yaml:"active_state"`
+	SubState             string `yaml:"sub_state"`
+	Enabled              *bool
+	MaxRestarts          *int `yaml:"max_restarts"`
+	Systemctl            string
+	Contains             []string
+	NotContains          []string `yaml:"not_contains"`
+	Regex                string
+	MaxReadSize          string        `yaml:"max_read_size"`
+	MD5                  string        `yaml:"md5"`
+	SHA256               string        `yaml:"sha256"`
+	SHA512               string        `yaml:"sha512"`
+	ChecksumFile         string        `yaml:"checksum_file"`
+	AgeOf                string        `yaml:"age_of"`
+	MinAge               time.Duration `yaml:"min_age"`
+	MaxAge               time.Duration `yaml:"max_age"`
+	NewestMaxAge         time.Duration `yaml:"newest_max_age"`
+	Recursive            bool
+	MaxDepth             int `yaml:"max_depth"`
+	Include              string
+	MaxTotalSize         string `yaml:"max_total_size"`
+	Send                 string `yaml:"send"`
+	Expect               string `yaml:"expect"`
+	ExpectRegex          string `yaml:"expect_regex"`
+	RecordType           string `yaml:"record_type"`
+	Server               string
+	Answers              []string
+	Port                 int
+	NoWildcard           bool `yaml:"no_wildcard"`
+	Command              []string
+	Env                  map[string]string
+	Dir                  string
+	ExitCode             *int   `yaml:"exit_code"`
+	StdoutContains       string `yaml:"stdout_contains"`
+	StdoutRegex          string `yaml:"stdout_regex"`
+	StderrContains       string `yaml:"stderr_contains"`
+	StderrRegex          string `yaml:"stderr_regex"`
 }
 
 // ReadConf reads the configuration file and returns a pointer to Conf struct
@@ -65,6 +152,32 @@ func ReadConf(path string) (*Conf, error) {
 	return &conf, err
 }
 
+// UnmarshalYAML decodes the suite from a list of check specs, or a mapping with the suite options
+func (suite *ConfCheckSpecSuite) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		return value.Decode(&suite.Checks)
+	}
+	type plainSuite ConfCheckSpecSuite // avoid recursive calls to UnmarshalYAML
+	return value.Decode((*plainSuite)(suite))
+}
+
+// ConfFileMode is a file permission mode, written in octal with or without a leading 0 or 0o
+// (e.g. 755, 0755, 0o755 or "755"), as with chmod
+type ConfFileMode uint32
+
+// UnmarshalYAML decodes the mode from the octal digits of the scalar, instead of decimal 755 (0o1363)
+func (mode *ConfFileMode) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("invalid file mode at line %v, want octal digits", value.Line)
+	}
+	parsed, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(value.Value), "0o"), 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode %q, want octal digits", value.Value)
+	}
+	*mode = ConfFileMode(parsed)
+	return nil
+}
+
 // GetBaseConfRunner returns a base ConfRunner with default literal values
 func GetBaseConfRunner() ConfRunner {
 	var timeout, readTimeout, writeTimout time.Duration = 5 * time.Minute, 30 * time.Second, 30 * time.Second
@@ -72,6 +185,10 @@ func GetBaseConfRunner() ConfRunner {
 	var MaxConcurrentRequests int = 1
 	var respOK, respFailed, respTimeout string = "OK", "FAILED", "TIMEOUT"
 	var respUnavailable, respInvalidRequest string = "UNAVAILABLE", "INVALID REQUEST"
+	var respNotFound, respStale, respWarning string = "NOT FOUND", "STALE", "WARNING"
+	var respWarningStatus int = http.StatusOK
+	var interval, maxResultAge time.Duration = 0, 0
+	var output string = OutputText
 
 	baseConf := ConfRunner{
 		Timeout:                &timeout,
@@ -86,7 +203,14 @@ func GetBaseConfRunner() ConfRunner {
 		ResponseTimeout:        &respTimeout,
 		ResponseInvalidRequest: &respInvalidRequest,
 		ResponseUnavailable:    &respUnavailable,
+		ResponseNotFound:       &respNotFound,
+		ResponseStale:          &respStale,
+		ResponseWarning:        &respWarning,
+		ResponseWarningStatus:  &respWarningStatus,
+		Interval:               &interval,
+		MaxResultAge:           &maxResultAge,
 		MaxConcurrentRequests:  &MaxConcurrentRequests,
+		Output:                 &output,
 	}
 	return baseConf
 }
@@ -135,6 +259,9 @@ func MergedConfRunners(baseConf, overrideConf *ConfRunner) ConfRunner {
 	if mergedConf.MaxConcurrentRequests == nil {
 		mergedConf.MaxConcurrentRequests = baseConf.MaxConcurrentRequests
 	}
+	if mergedConf.Output == nil {
+		mergedConf.Output = baseConf.Output
+	}
 
 	mergeConfRunnerTimeouts(&mergedConf, baseConf)
 
@@ -161,6 +288,12 @@ func mergeConfRunnerTimeouts(mergedConf, baseConf *ConfRunner) {
 	if mergedConf.ResponseWriteTimeout == nil {
 		mergedConf.ResponseWriteTimeout = baseConf.ResponseWriteTimeout
 	}
+	if mergedConf.Interval == nil {
+		mergedConf.Interval = baseConf.Interval
+	}
+	if mergedConf.MaxResultAge == nil {
+		mergedConf.MaxResultAge = baseConf.MaxResultAge
+	}
 }
 
 // mergeConfRunnerResponses merges the response fields of the mergedConf with the baseConf in place
@@ -184,6 +317,22 @@ func mergeConfRunnerResponses(mergedConf, baseConf *ConfRunner) {
 	if mergedConf.ResponseInvalidRequest == nil {
 		mergedConf.ResponseInvalidRequest = baseConf.ResponseInvalidRequest
 	}
+
+	if mergedConf.ResponseNotFound == nil {
+		mergedConf.ResponseNotFound = baseConf.ResponseNotFound
+	}
+
+	if mergedConf.ResponseStale == nil {
+		mergedConf.ResponseStale = baseConf.ResponseStale
+	}
+
+	if mergedConf.ResponseWarning == nil {
+		mergedConf.ResponseWarning = baseConf.ResponseWarning
+	}
+
+	if mergedConf.ResponseWarningStatus == nil {
+		mergedConf.ResponseWarningStatus = baseConf.ResponseWarningStatus
+	}
 }
 
 // CopyConfRunner returns a copy of the ConfRunner with the same values
@@ -200,8 +349,15 @@ func CopyConfRunner(conf *ConfRunner) ConfRunner {
 		ResponseTimeout:        conf.ResponseTimeout,
 		ResponseUnavailable:    conf.ResponseUnavailable,
 		ResponseInvalidRequest: conf.ResponseInvalidRequest,
+		ResponseNotFound:       conf.ResponseNotFound,
+		ResponseStale:          conf.ResponseStale,
+		ResponseWarning:        conf.ResponseWarning,
+		ResponseWarningStatus:  conf.ResponseWarningStatus,
+		Interval:               conf.Interval,
+		MaxResultAge:           conf.MaxResultAge,
 		MaxHeaderBytes:         conf.MaxHeaderBytes,
 		MaxConcurrentRequests:  conf.MaxConcurrentRequests,
+		Output:                 conf.Output,
 	}
 	maps.Copy(newConfRunner.RequestRequiredHeaders, conf.RequestRequiredHeaders)
 	return newConfRunner
@@ -2,8 +2,11 @@ package chkok
 
 import (
 	"maps"
+	"net/http"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TestReadConfErrors tests the ReadConf function for error handling
@@ -43,21 +46,27 @@ func TestReadConf(t *testing.T) {
 	if !ok {
 		t.Errorf("read conf found no etc checks")
 	}
-	if len(etcChecks) != 3 {
-		t.Errorf("read conf etc checks failed, want 3 checks found %v", len(etcChecks))
+	if len(etcChecks.Checks) != 3 {
+		t.Errorf("read conf etc checks failed, want 3 checks found %v", len(etcChecks.Checks))
+	}
+	if !etcChecks.StopOnFailure {
+		t.Errorf("read conf etc checks failed, want stop on failure")
 	}
 	defaultChecks, ok := conf.CheckSuites["default"]
 	if !ok {
 		t.Errorf("read conf found no default checks")
 	}
-	if len(defaultChecks) != 3 {
-		t.Errorf("read conf default checks failed, want 3 checks found %v", len(etcChecks))
+	if len(defaultChecks.Checks) != 3 {
+		t.Errorf("read conf default checks failed, want 3 checks found %v", len(etcChecks.Checks))
+	}
+	if defaultChecks.StopOnFailure {
+		t.Errorf("read conf default checks failed, want no stop on failure")
 	}
 	invalidChecks, ok := conf.CheckSuites["invalid"]
 	if ok {
 		t.Errorf("read conf found invalid check group but shouldn't")
 	}
-	if len(invalidChecks) > 0 {
+	if len(invalidChecks.Checks) > 0 {
 		t.Errorf("read conf found invalid checks but shouldn't")
 	}
 }
@@ -129,6 +138,12 @@ func TestGetDefaultConfRunner(t *testing.T) {
 	if *defaultRunner.ResponseTimeout != *baseRunner.ResponseTimeout {
 		t.Errorf("ResponseTimeout want %v, got %s", *baseRunner.ResponseTimeout, *defaultRunner.ResponseTimeout)
 	}
+	if *defaultRunner.ResponseNotFound != *baseRunner.ResponseNotFound {
+		t.Errorf("ResponseNotFound want %v, got %s", *baseRunner.ResponseNotFound, *defaultRunner.ResponseNotFound)
+	}
+	if *defaultRunner.ResponseWarningStatus != http.StatusOK {
+		t.Errorf("ResponseWarningStatus want %v, got %v", http.StatusOK, *defaultRunner.ResponseWarningStatus)
+	}
 	if *defaultRunner.MaxHeaderBytes != *baseRunner.MaxHeaderBytes {
 		t.Errorf("MaxHeaderBytes want %v, got %v", *baseRunner.MaxHeaderBytes, *defaultRunner.MaxHeaderBytes)
 	}
@@ -289,3 +304,33 @@ func TestGetConfRunnerAllowOverridesWithZeroValue(t *testing.T) {
 		t.Errorf("expected ListenAddress to be set by default if not configured")
 	}
 }
+
+func TestConfFileMode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		yaml      string
+		wantMode  ConfFileMode
+		expectErr bool
+	}{
+		{"Without leading 0", "mode: 755", 0o755, false},
+		{"Leading 0", "mode: 0755", 0o755, false},
+		{"Leading 0o", "mode: 0o4755", 0o4755, false},
+		{"Quoted", `mode: "644"`, 0o644, false},
+		{"Zero", "mode: 0", 0, false},
+		{"Not octal", "mode: 789", 0, true},
+		{"Hex", "mode: 0x1ed", 0, true},
+		{"Not a scalar", "mode: [7, 5, 5]", 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec ConfCheckSpec
+			err := yaml.Unmarshal([]byte(tc.yaml), &spec)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("want err %v, got %v", tc.expectErr, err)
+			}
+			if !tc.expectErr && (spec.Mode == nil || *spec.Mode != tc.wantMode) {
+				t.Errorf("want mode %o, got %v", tc.wantMode, spec.Mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package chkok
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// HTTPMetrics is the counters of the http requests handled in HTTP mode
+type HTTPMetrics struct {
+	Served   uint64 // requests that ran the checks
+	Rejected uint64 // requests rejected by the max concurrent requests limit
+}
+
+// prometheusLabelReplacer escapes label values in Prometheus text format
+var prometheusLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheusMetrics writes the report and http metrics in Prometheus text exposition format
+func WritePrometheusMetrics(output io.Writer, report *Report, httpMetrics *HTTPMetrics) error {
+	writer := bufio.NewWriter(output)
+	writeMetricHeader(writer, "chkok_check_ok", "gauge", "Whether the check passed (1) or not (0).")
+	for index := range report.Checks {
+		chk := &report.Checks[index]
+		value := 0
+		if chk.IsOK {
+			value = 1
+		}
+		fmt.Fprintf(writer, "chkok_check_ok{%s} %d\n", checkMetricLabels(chk), value)
+	}
+	writeMetricHeader(writer, "chkok_check_warning", "gauge", "Whether the check passed with warnings (1) or not (0).")
+	for index := range report.Checks {
+		chk := &report.Checks[index]
+		value := 0
+		if chk.IsOK && len(chk.Warnings) > 0 {
+			value = 1
+		}
+		fmt.Fprintf(writer, "chkok_check_warning{%s} %d\n", checkMetricLabels(chk), value)
+	}
+	writeMetricHeader(writer, "chkok_check_duration_seconds", "gauge", "Duration of the latest run of the check.")
+	for index := range report.Checks {
+		chk := &report.Checks[index]
+		fmt.Fprintf(writer, "chkok_check_duration_seconds{%s} %g\n", checkMetricLabels(chk), chk.DurationSeconds)
+	}
+	writeMetricHeader(writer, "chkok_check_status", "gauge",
+		"Status of the latest run of the check (unknown, running, stopped, done, skipped).")
+	for index := range report.Checks {
+		chk := &report.Checks[index]
+		fmt.Fprintf(writer, "chkok_check_status{%s,status=\"%s\"} 1\n", checkMetricLabels(chk), chk.Status)
+	}
+	writeMetricHeader(writer, "chkok_http_requests_served_total", "counter",
+		"Number of http requests that ran the checks.")
+	fmt.Fprintf(writer, "chkok_http_requests_served_total %d\n", httpMetrics.Served)
+	writeMetricHeader(writer, "chkok_http_requests_rejected_total", "counter",
+		"Number of http requests rejected by the max concurrent requests limit.")
+	fmt.Fprintf(writer, "chkok_http_requests_rejected_total %d\n", httpMetrics.Rejected)
+	return writer.Flush()
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric
+func writeMetricHeader(writer io.Writer, name, metricType, help string) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// checkMetricLabels returns the suite and name labels of the check metrics
+func checkMetricLabels(chk *ReportCheck) string {
+	return fmt.Sprintf("suite=\"%s\",name=\"%s\"",
+		prometheusLabelReplacer.Replace(chk.Suite), prometheusLabelReplacer.Replace(chk.Name))
+}
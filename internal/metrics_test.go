@@ -0,0 +1,36 @@
+package chkok
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	report := Report{Checks: []ReportCheck{
+		{Suite: "web", Name: `http:GET http://localhost/?q="a\b"`, Status: "done", IsOK: true, DurationSeconds: 0.25},
+		{Suite: "web", Name: "tls:localhost:443", Status: "stopped", IsOK: false, DurationSeconds: 2},
+	}}
+	var buf bytes.Buffer
+	if err := WritePrometheusMetrics(&buf, &report, &HTTPMetrics{Served: 5, Rejected: 1}); err != nil {
+		t.Fatalf("write prometheus metrics want no err, got %v", err)
+	}
+	got := buf.String()
+	wantLines := []string{
+		"# TYPE chkok_check_ok gauge",
+		`chkok_check_ok{suite="web",name="http:GET http://localhost/?q=\"a\\b\""} 1`,
+		`chkok_check_ok{suite="web",name="tls:localhost:443"} 0`,
+		"# TYPE chkok_check_duration_seconds gauge",
+		`chkok_check_duration_seconds{suite="web",name="http:GET http://localhost/?q=\"a\\b\""} 0.25`,
+		`chkok_check_duration_seconds{suite="web",name="tls:localhost:443"} 2`,
+		`chkok_check_status{suite="web",name="tls:localhost:443",status="stopped"} 1`,
+		"# TYPE chkok_http_requests_served_total counter",
+		"chkok_http_requests_served_total 5",
+		"chkok_http_requests_rejected_total 1",
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("want metrics to contain %q, got %v", line, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package chkok
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Nagios plugin states, used as exit codes with the nagios output
+const (
+	NagiosOK       = 0
+	NagiosWarning  = 1
+	NagiosCritical = 2
+	NagiosUnknown  = 3
+)
+
+// nagiosStateNames are the names of the nagios plugin states, used in the status line
+var nagiosStateNames = map[int]string{
+	NagiosOK:       "OK",
+	NagiosWarning:  "WARNING",
+	NagiosCritical: "CRITICAL",
+	NagiosUnknown:  "UNKNOWN",
+}
+
+// NagiosState returns the nagios plugin state of the report.
+// Failed checks are critical, checks that timedout (without failures) are unknown,
+// and checks that passed with warnings are warning.
+func NagiosState(report *Report) int {
+	if report.Totals.Failed > 0 {
+		return NagiosCritical
+	}
+	if report.Totals.Timedout > 0 {
+		return NagiosUnknown
+	}
+	if report.Totals.Warned > 0 {
+		return NagiosWarning
+	}
+	return NagiosOK
+}
+
+// WriteNagiosOutput writes the report in nagios plugin format, a status line with performance data
+// followed by details of the checks that did not pass or have warnings. Returns the nagios state of the report.
+func WriteNagiosOutput(output io.Writer, report *Report) (int, error) {
+	state := NagiosState(report)
+	writer := bufio.NewWriter(output)
+	fmt.Fprintf(writer, "CHKOK %s - %s", nagiosStateNames[state], reportSummary(report))
+	perfdata := nagiosPerfdata(report)
+	if len(perfdata) > 0 {
+		fmt.Fprintf(writer, " | %s", strings.Join(perfdata, " "))
+	}
+	fmt.Fprintln(writer)
+	for _, chk := range report.Checks {
+		if chk.IsOK && len(chk.Warnings) == 0 {
+			continue
+		}
+		details := append(append([]string{}, chk.Issues...), chk.Warnings...)
+		fmt.Fprintf(writer, "%s %s (%s): %s\n", chk.Suite, chk.Name, chk.Status, strings.Join(details, "; "))
+	}
+	return state, writer.Flush()
+}
+
+// nagiosPerfdata returns the performance data of the checks, their durations and metrics
+func nagiosPerfdata(report *Report) []string {
+	var perfdata []string
+	for _, chk := range report.Checks {
+		perfdata = append(perfdata, nagiosPerfdataItem(&chk, "duration", chk.DurationSeconds, "s"))
+		for _, metric := range chk.Metrics {
+			perfdata = append(perfdata, nagiosPerfdataItem(&chk, metric.Name, metric.Value, metric.Unit))
+		}
+	}
+	return perfdata
+}
+
+// nagiosPerfdataItem returns a single quoted label performance data item of the check
+func nagiosPerfdataItem(chk *ReportCheck, name string, value float64, unit string) string {
+	label := strings.ReplaceAll(fmt.Sprintf("%s/%s %s", chk.Suite, chk.Name, name), "'", "''")
+	return fmt.Sprintf("'%s'=%s%s;;;0", label, strconv.FormatFloat(value, 'f', -1, 64), unit)
+}
@@ -0,0 +1,58 @@
+package chkok
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteNagiosOutput(t *testing.T) {
+	report := Report{Checks: []ReportCheck{
+		{Suite: "etc", Name: "file:/etc/app's.conf", Status: "done", IsOK: true, DurationSeconds: 0.25,
+			Metrics: []Metric{{Name: "size", Value: 1024, Unit: "B"}}},
+		{Suite: "var", Name: "dir:/var/spool", Status: "done", IsOK: false, DurationSeconds: 0.5,
+			Issues:  []string{"directory contains too many files", "mode mismatch"},
+			Metrics: []Metric{{Name: "file_count", Value: 12}}},
+	}}
+	report.Totals = reportTotals(report.Checks)
+	var buf bytes.Buffer
+	state, err := WriteNagiosOutput(&buf, &report)
+	if err != nil {
+		t.Fatalf("write nagios output want no err, got %v", err)
+	}
+	if state != NagiosCritical {
+		t.Errorf("nagios state want %v got %v", NagiosCritical, state)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := "CHKOK CRITICAL - 1/2 checks failed | 'etc/file:/etc/app''s.conf duration'=0.25s;;;0 " +
+		"'etc/file:/etc/app''s.conf size'=1024B;;;0 'var/dir:/var/spool duration'=0.5s;;;0 " +
+		"'var/dir:/var/spool file_count'=12;;;0"
+	if len(lines) != 2 || lines[0] != want {
+		t.Fatalf("nagios status line want %q, got %q", want, lines)
+	}
+	want = "var dir:/var/spool (done): directory contains too many files; mode mismatch"
+	if lines[1] != want {
+		t.Errorf("nagios long output want %q, got %q", want, lines[1])
+	}
+}
+
+func TestNagiosState(t *testing.T) {
+	testCases := []struct {
+		name   string
+		totals ReportTotals
+		want   int
+	}{
+		{"Passed", ReportTotals{Total: 2, Passed: 2}, NagiosOK},
+		{"Failed", ReportTotals{Total: 2, Passed: 1, Failed: 1}, NagiosCritical},
+		{"Failed and timedout", ReportTotals{Total: 2, Failed: 1, Timedout: 1}, NagiosCritical},
+		{"Timedout", ReportTotals{Total: 2, Passed: 1, Timedout: 1}, NagiosUnknown},
+		{"Warned", ReportTotals{Total: 2, Passed: 1, Warned: 1}, NagiosWarning},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NagiosState(&Report{Totals: tc.totals}); got != tc.want {
+				t.Errorf("nagios state want %v got %v", tc.want, got)
+			}
+		})
+	}
+}
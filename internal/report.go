@@ -0,0 +1,145 @@
+package chkok
+
+import "slices"
+
+// ReportCheck is the report of a check after a run
+type ReportCheck struct {
+	Suite           string   `json:"suite"`
+	Name            string   `json:"name"`
+	Status          string   `json:"status"`
+	IsOK            bool     `json:"is_ok"`
+	Issues          []string `json:"issues"`
+	Warnings        []string `json:"warnings"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Metrics         []Metric `json:"metrics"`
+}
+
+// ReportTotals is the number of checks by their outcome
+type ReportTotals struct {
+	Total    int `json:"total"`
+	Passed   int `json:"passed"`
+	Warned   int `json:"warned"` // passed with warnings
+	Failed   int `json:"failed"`
+	Timedout int `json:"timedout"`
+	Skipped  int `json:"skipped"`
+}
+
+// Report is the report of all the checks after a run
+type Report struct {
+	IsOK   bool          `json:"is_ok"`
+	Totals ReportTotals  `json:"totals"`
+	Checks []ReportCheck `json:"checks"`
+}
+
+// ReportSuite is the report of the checks in a suite
+type ReportSuite struct {
+	Name            string        `json:"name"`
+	IsOK            bool          `json:"is_ok"`
+	Totals          ReportTotals  `json:"totals"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Checks          []ReportCheck `json:"checks"`
+}
+
+// SuitesReport is the report of all the checks after a run, grouped by suite
+type SuitesReport struct {
+	IsOK   bool          `json:"is_ok"`
+	Totals ReportTotals  `json:"totals"`
+	Suites []ReportSuite `json:"suites"`
+}
+
+// NewReportCheck returns the report of the check based on its latest run
+func NewReportCheck(chk Check) ReportCheck {
+	result := chk.Result()
+	issues := make([]string, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		issues = append(issues, issue.Error())
+	}
+	warnings := make([]string, 0, len(result.Warnings))
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, warning.Error())
+	}
+	return ReportCheck{
+		Suite:           chk.Suite(),
+		Name:            chk.Name(),
+		Status:          chk.Status().String(),
+		IsOK:            result.IsOK,
+		Issues:          issues,
+		Warnings:        warnings,
+		DurationSeconds: chk.Duration().Seconds(),
+		Metrics:         append([]Metric{}, result.Metrics...),
+	}
+}
+
+// NewReport returns the report of the checks based on their latest run
+func NewReport(checks []Check) Report {
+	report := Report{Checks: make([]ReportCheck, 0, len(checks))}
+	for _, chk := range checks {
+		report.Checks = append(report.Checks, NewReportCheck(chk))
+	}
+	report.Totals = reportTotals(report.Checks)
+	report.IsOK = report.Totals.isOK()
+	return report
+}
+
+// FilterReport returns a report of only the checks in the suites
+func FilterReport(report *Report, suites []string) Report {
+	filtered := Report{Checks: []ReportCheck{}}
+	for _, chk := range report.Checks {
+		if slices.Contains(suites, chk.Suite) {
+			filtered.Checks = append(filtered.Checks, chk)
+		}
+	}
+	filtered.Totals = reportTotals(filtered.Checks)
+	filtered.IsOK = filtered.Totals.isOK()
+	return filtered
+}
+
+// NewSuitesReport returns the report grouping the checks by suite, in order of the checks
+func NewSuitesReport(report *Report) SuitesReport {
+	suitesReport := SuitesReport{IsOK: report.IsOK, Totals: report.Totals, Suites: []ReportSuite{}}
+	indexes := map[string]int{}
+	for _, chk := range report.Checks {
+		index, ok := indexes[chk.Suite]
+		if !ok {
+			index = len(suitesReport.Suites)
+			indexes[chk.Suite] = index
+			suitesReport.Suites = append(suitesReport.Suites, ReportSuite{Name: chk.Suite, Checks: []ReportCheck{}})
+		}
+		suite := &suitesReport.Suites[index]
+		suite.Checks = append(suite.Checks, chk)
+		suite.DurationSeconds += chk.DurationSeconds // checks of a suite run sequentially
+	}
+	for index := range suitesReport.Suites {
+		suite := &suitesReport.Suites[index]
+		suite.Totals = reportTotals(suite.Checks)
+		suite.IsOK = suite.Totals.isOK()
+	}
+	return suitesReport
+}
+
+// isOK returns true if all the checks passed, with or without warnings
+func (totals ReportTotals) isOK() bool {
+	return totals.Passed+totals.Warned == totals.Total
+}
+
+// reportTotals returns the number of checks by their outcome
+func reportTotals(checks []ReportCheck) ReportTotals {
+	totals := ReportTotals{Total: len(checks)}
+	for _, chk := range checks {
+		switch chk.Status {
+		case StatusDone.String():
+			if chk.IsOK && len(chk.Warnings) > 0 {
+				totals.Warned++
+			} else if chk.IsOK {
+				totals.Passed++
+			} else {
+				totals.Failed++
+			}
+		case StatusSkipped.String():
+			totals.Skipped++
+		default:
+			totals.Timedout++
+		}
+	}
+	return totals
+}
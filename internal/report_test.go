@@ -0,0 +1,81 @@
+package chkok
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatusString(t *testing.T) {
+	testCases := map[Status]string{
+		StatusUnknown: "unknown",
+		StatusRunning: "running",
+		StatusStopped: "stopped",
+		StatusDone:    "done",
+		StatusSkipped: "skipped",
+		Status(100):   "status(100)",
+	}
+	for status, want := range testCases {
+		if got := status.String(); got != want {
+			t.Errorf("status string want %v got %v", want, got)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	passed := NewCheckFile("../LICENSE")
+	passed.setSuite("files")
+	passed.Run()
+	passed.setDuration(1500 * time.Millisecond)
+	failed := NewCheckFile("/no/such/path/exists")
+	failed.Run()
+	skipped := NewCheckFile("../LICENSE")
+	skipped.skip(errors.New("skip reason"))
+	notRun := NewCheckDial()
+
+	report := NewReport([]Check{passed, failed, skipped, notRun})
+	want := ReportTotals{Total: 4, Passed: 1, Failed: 1, Timedout: 1, Skipped: 1}
+	if report.Totals != want {
+		t.Errorf("report totals want %+v got %+v", want, report.Totals)
+	}
+	if report.IsOK {
+		t.Errorf("report want not ok, got ok")
+	}
+	got := report.Checks[0]
+	if got.Suite != "files" || got.Name != "any:../LICENSE" || got.Status != "done" || !got.IsOK {
+		t.Errorf("report check want passed check in files suite, got %+v", got)
+	}
+	if got.DurationSeconds != 1.5 || len(got.Issues) != 0 {
+		t.Errorf("report check want 1.5s duration and no issues, got %+v", got)
+	}
+	if got = report.Checks[2]; got.Status != "skipped" || len(got.Issues) != 1 || got.Issues[0] != "skip reason" {
+		t.Errorf("report check want skipped with reason, got %+v", got)
+	}
+
+	report = NewReport([]Check{passed})
+	if !report.IsOK {
+		t.Errorf("report want ok, got not ok")
+	}
+}
+
+func TestFilterReport(t *testing.T) {
+	passed := NewCheckFile("../LICENSE")
+	passed.setSuite("files")
+	passed.Run()
+	failed := NewCheckFile("/no/such/path/exists")
+	failed.setSuite("missing")
+	failed.Run()
+	report := NewReport([]Check{passed, failed})
+
+	filtered := FilterReport(&report, []string{"files"})
+	if !filtered.IsOK || filtered.Totals != (ReportTotals{Total: 1, Passed: 1}) {
+		t.Errorf("filtered report want 1 passed check, got %+v", filtered)
+	}
+	if len(filtered.Checks) != 1 || filtered.Checks[0].Suite != "files" {
+		t.Errorf("filtered report want only files suite checks, got %+v", filtered.Checks)
+	}
+	filtered = FilterReport(&report, []string{"files", "missing"})
+	if filtered.IsOK || filtered.Totals != report.Totals {
+		t.Errorf("filtered report of all suites want totals %+v, got %+v", report.Totals, filtered.Totals)
+	}
+}
@@ -2,10 +2,16 @@ package chkok
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"mime"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 )
@@ -15,23 +21,76 @@ const (
 	ShutdownTimeout = 5 * time.Second
 )
 
+// WarningsHeader is the http response header of the number of checks passed with warnings
+const WarningsHeader = "X-Chkok-Warnings"
+
+// ResultAgeHeader is the http response header of the age (in seconds) of the results of scheduled runs
+const ResultAgeHeader = "X-Chkok-Result-Age"
+
+const (
+	// OutputText is the output format for a human readable summary
+	OutputText = "text"
+	// OutputJSON is the output format for a JSON document of the report
+	OutputJSON = "json"
+	// OutputNagios is the output format of nagios plugins, with nagios exit codes in CLI mode
+	OutputNagios = "nagios"
+)
+
+// OutputFormats is the list of supported output formats
+var OutputFormats = []string{OutputText, OutputJSON, OutputNagios}
+
 // RunModeCLI run app in CLI mode using the provided configs, return exit code
 func RunModeCLI(checkGroups *CheckSuites, conf *ConfRunner, output io.Writer, logger *log.Logger) int {
 	runner := Runner{Log: logger, Timeout: *conf.Timeout}
-	passed, failed, timedout := runChecks(&runner, checkGroups, logger)
-	total := passed + failed + timedout
-	if timedout > 0 {
-		fmt.Fprintf(output, "%v/%v checks timedout", timedout, total)
+	report := runChecks(&runner, checkGroups, logger)
+	switch *conf.Output {
+	case OutputNagios:
+		state, err := WriteNagiosOutput(output, &report)
+		if err != nil {
+			logger.Printf("failed to write nagios output: %v", err)
+			return NagiosUnknown
+		}
+		return state
+	case OutputJSON:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			logger.Printf("failed to write json output: %v", err)
+			return ExSoftware
+		}
+	default:
+		fmt.Fprint(output, reportSummary(&report))
+	}
+	if report.Totals.Timedout > 0 {
 		return ExTempFail
 	}
-	if failed > 0 {
-		fmt.Fprintf(output, "%v/%v checks failed", failed, total)
+	if report.Totals.Failed > 0 {
 		return ExSoftware
 	}
-	fmt.Fprintf(output, "%v checks passed", total)
+	if report.Totals.Warned > 0 {
+		return ExWarning
+	}
 	return ExOK
 }
 
+// reportSummary returns a one line summary of the report
+func reportSummary(report *Report) string {
+	totals := report.Totals
+	if totals.Timedout > 0 {
+		return fmt.Sprintf("%v/%v checks timedout", totals.Timedout, totals.Total)
+	}
+	if totals.Failed > 0 {
+		if totals.Skipped > 0 {
+			return fmt.Sprintf("%v/%v checks failed, %v skipped", totals.Failed, totals.Total, totals.Skipped)
+		}
+		return fmt.Sprintf("%v/%v checks failed", totals.Failed, totals.Total)
+	}
+	if totals.Warned > 0 {
+		return fmt.Sprintf("%v checks passed, %v with warnings", totals.Total, totals.Warned)
+	}
+	return fmt.Sprintf("%v checks passed", totals.Total)
+}
+
 func httpRequestAsString(r *http.Request) string {
 	return fmt.Sprintf("%s - %s %s %s", r.RemoteAddr, r.Proto, r.Method, r.URL)
 }
@@ -45,12 +104,11 @@ func RunModeHTTP(checkGroups *CheckSuites, conf *ConfRunner, logger *log.Logger)
 
 	var reqHandlerChan = make(chan *http.Request, 1)
 
-	httpHandler := makeHTTPRequestHandler(reqHandlerChan, conf, checkGroups, logger)
-	http.HandleFunc("/", httpHandler)
+	handlers := newHTTPHandlers(reqHandlerChan, conf, checkGroups, logger)
 
 	server := &http.Server{
 		Addr:           conf.ListenAddress,
-		Handler:        nil, // use http.DefaultServeMux
+		Handler:        handlers.serveMux(),
 		ReadTimeout:    *conf.RequestReadTimeout,
 		WriteTimeout:   *conf.ResponseWriteTimeout,
 		IdleTimeout:    0 * time.Second, // set to 0 so uses read timeout
@@ -59,6 +117,13 @@ func RunModeHTTP(checkGroups *CheckSuites, conf *ConfRunner, logger *log.Logger)
 
 	var count uint32 = 0
 
+	scheduleCtx, cancelSchedule := context.WithCancel(context.Background())
+	defer cancelSchedule()
+	if handlers.interval > 0 {
+		logger.Printf("running checks in background every %v", handlers.interval)
+		go handlers.runScheduled(scheduleCtx)
+	}
+
 	go func() {
 		var request *http.Request
 		timeoutCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
@@ -88,78 +153,236 @@ func RunModeHTTP(checkGroups *CheckSuites, conf *ConfRunner, logger *log.Logger)
 	return ExOK
 }
 
-// makeHTTPRequestHandler creates a http request handler function used by RunModeHTTP
-func makeHTTPRequestHandler(reqHandlerChan chan *http.Request,
-	conf *ConfRunner, checkGroups *CheckSuites, logger *log.Logger) func(http.ResponseWriter, *http.Request) {
-	maxConcurrentRequests := int32(*conf.MaxConcurrentRequests) //nolint: gosec
-	responseOK, responseFailed := *conf.ResponseOK, *conf.ResponseFailed
-	responseTimeout := *conf.ResponseTimeout
-	responseUnavailable, responseInvalidRequest := *conf.ResponseUnavailable, *conf.ResponseInvalidRequest
-	requieredHeaders := conf.RequestRequiredHeaders
-	shouldCheckHeaders := len(requieredHeaders) > 0
+// httpHandlers handles http requests of RunModeHTTP by running the checks,
+// limiting the number of concurrent requests
+type httpHandlers struct {
+	reqHandlerChan   chan *http.Request
+	conf             *ConfRunner
+	checkGroups      *CheckSuites
+	runner           Runner
+	logger           *log.Logger
+	runningRequests  atomic.Int32
+	servedRequests   atomic.Uint64
+	rejectedRequests atomic.Uint64
+	interval         time.Duration // run checks in background every interval, 0 to run on each request
+	maxResultAge     time.Duration // max age of background run results before they're stale, 0 to skip
+	latestReport     atomic.Pointer[scheduledReport]
+}
 
-	runner := Runner{Log: logger, Timeout: *conf.Timeout}
+// scheduledReport is the report of a background run of the checks, and when the run completed
+type scheduledReport struct {
+	report    Report
+	completed time.Time
+}
+
+// newHTTPHandlers creates the http request handlers used by RunModeHTTP
+func newHTTPHandlers(reqHandlerChan chan *http.Request, conf *ConfRunner, checkGroups *CheckSuites,
+	logger *log.Logger) *httpHandlers {
+	return &httpHandlers{
+		reqHandlerChan: reqHandlerChan,
+		conf:           conf,
+		checkGroups:    checkGroups,
+		runner:         Runner{Log: logger, Timeout: *conf.Timeout},
+		logger:         logger,
+		interval:       *conf.Interval,
+		maxResultAge:   *conf.MaxResultAge,
+	}
+}
+
+// runScheduled runs all the checks every interval, storing the latest report, until the context is done
+func (h *httpHandlers) runScheduled(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		report := runChecks(&h.runner, h.checkGroups, h.logger)
+		h.latestReport.Store(&scheduledReport{report: report, completed: time.Now()})
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveMux returns the http request multiplexer routing the request paths to the handlers
+func (h *httpHandlers) serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.checksHandler)
+	mux.HandleFunc("/suites/{suite}", h.checksHandler)
+	mux.HandleFunc("/metrics", h.metricsHandler)
+	return mux
+}
 
-	var runningRequests atomic.Int32
-	httpRequestHandler := func(w http.ResponseWriter, r *http.Request) {
-		runningRequests.Add(1)
-		if maxConcurrentRequests > 0 && runningRequests.Load() > maxConcurrentRequests {
-			logger.Printf("runner reached max conccurent requests. rejecting request: %s", httpRequestAsString(r))
+// checksHandler runs the checks and responds with the result in plain text or JSON
+func (h *httpHandlers) checksHandler(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, func(report *Report) {
+		conf := h.conf
+		statusCode, body := http.StatusOK, *conf.ResponseOK
+		if report.Totals.Timedout > 0 {
+			statusCode, body = http.StatusGatewayTimeout, *conf.ResponseTimeout // 504
+		} else if report.Totals.Failed > 0 {
+			statusCode, body = http.StatusInternalServerError, *conf.ResponseFailed // 500
+		} else if report.Totals.Warned > 0 {
+			statusCode, body = *conf.ResponseWarningStatus, *conf.ResponseWarning
+			w.Header().Set(WarningsHeader, strconv.Itoa(report.Totals.Warned))
+		}
+		if *conf.Output == OutputJSON || acceptsJSON(r) {
+			writeJSONResponse(w, statusCode, NewSuitesReport(report), h.logger)
+		} else {
+			w.WriteHeader(statusCode)
+			fmt.Fprint(w, body)
+		}
+	})
+}
+
+// metricsHandler runs the checks (or uses results of the background runs) and responds with the
+// metrics in Prometheus text format
+func (h *httpHandlers) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handle(w, r, func(report *Report) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		w.WriteHeader(http.StatusOK)
+		metrics := HTTPMetrics{Served: h.servedRequests.Load(), Rejected: h.rejectedRequests.Load()}
+		if err := WritePrometheusMetrics(w, report, &metrics); err != nil {
+			h.logger.Printf("failed to write metrics response: %v", err)
+		}
+	})
+}
+
+// handle validates and limits the http request, runs the checks (or uses results of
+// the background runs) and responds with the respond func
+func (h *httpHandlers) handle(w http.ResponseWriter, r *http.Request, respond func(report *Report)) {
+	if h.interval <= 0 { // checks run on each request, so limit concurrent runs
+		maxConcurrentRequests := int32(*h.conf.MaxConcurrentRequests) //nolint: gosec
+		running := h.runningRequests.Add(1)
+		defer h.runningRequests.Add(-1)
+		if maxConcurrentRequests > 0 && running > maxConcurrentRequests {
+			h.rejectedRequests.Add(1)
+			h.logger.Printf("runner reached max conccurent requests. rejecting request: %s", httpRequestAsString(r))
 			w.WriteHeader(http.StatusServiceUnavailable) // 503
-			fmt.Fprint(w, responseUnavailable)
+			fmt.Fprint(w, *h.conf.ResponseUnavailable)
 			return
 		}
-		defer runningRequests.Add(-1)
-		if shouldCheckHeaders {
-			for header, value := range requieredHeaders {
-				reqHeader, ok := r.Header[header]
-				if !ok {
-					logger.Printf("http request missing required header %s: %s", header, httpRequestAsString(r))
-					w.WriteHeader(http.StatusBadRequest) // 400
-					fmt.Print(w, responseInvalidRequest)
-					return
-				}
-				if value != "" && reqHeader[0] != value {
-					logger.Printf("http request doesn't match required header %s: %s", header, httpRequestAsString(r))
-					w.WriteHeader(http.StatusBadRequest) // 400
-					fmt.Print(w, responseInvalidRequest)
-					return
-				}
-			}
+	}
+	if !h.hasRequiredHeaders(r) {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		fmt.Fprint(w, *h.conf.ResponseInvalidRequest)
+		return
+	}
+
+	checkGroups, unknown := h.requestedSuites(r)
+	if len(unknown) > 0 {
+		h.logger.Printf("http request for unknown suites %v: %s", unknown, httpRequestAsString(r))
+		w.WriteHeader(http.StatusNotFound) // 404
+		fmt.Fprint(w, *h.conf.ResponseNotFound)
+		return
+	}
+
+	h.logger.Printf("processing http request: %s", httpRequestAsString(r))
+	defer func() { h.reqHandlerChan <- r }() // after responding, even without results
+	var report Report
+	if h.interval > 0 {
+		var ok bool
+		if report, ok = h.scheduledReport(w, r, checkGroups); !ok {
+			return
 		}
+	} else {
+		report = runChecks(&h.runner, &checkGroups, h.logger)
+	}
+	h.servedRequests.Add(1)
+	respond(&report)
+}
 
-		logger.Printf("processing http request: %s", httpRequestAsString(r))
-		_, failed, timedout := runChecks(&runner, checkGroups, logger)
-		if timedout > 0 {
-			w.WriteHeader(http.StatusGatewayTimeout) // 504
-			fmt.Fprint(w, responseTimeout)
-		} else if failed > 0 {
-			w.WriteHeader(http.StatusInternalServerError) // 500
-			fmt.Fprint(w, responseFailed)
-		} else {
-			w.WriteHeader(http.StatusOK)
-			fmt.Fprint(w, responseOK)
+// scheduledReport returns the report of the latest background run for the check suites, setting the
+// result age header. Returns false after responding if there are no results yet or they're stale.
+func (h *httpHandlers) scheduledReport(w http.ResponseWriter, r *http.Request, checkGroups CheckSuites) (Report, bool) {
+	latest := h.latestReport.Load()
+	if latest == nil {
+		h.logger.Printf("no results from background runs yet for request: %s", httpRequestAsString(r))
+		w.WriteHeader(http.StatusServiceUnavailable) // 503
+		fmt.Fprint(w, *h.conf.ResponseUnavailable)
+		return Report{}, false
+	}
+	age := time.Since(latest.completed)
+	w.Header().Set(ResultAgeHeader, strconv.FormatFloat(age.Seconds(), 'f', 3, 64))
+	if h.maxResultAge > 0 && age > h.maxResultAge {
+		h.logger.Printf("results of background runs are stale (%v old) for request: %s", age, httpRequestAsString(r))
+		w.WriteHeader(http.StatusServiceUnavailable) // 503
+		fmt.Fprint(w, *h.conf.ResponseStale)
+		return Report{}, false
+	}
+	return FilterReport(&latest.report, slices.Collect(maps.Keys(checkGroups))), true
+}
+
+// requestedSuites returns the check suites selected by the request path or "suite" query params,
+// or all the suites if none is selected. Returns the names of selected suites that don't exist.
+func (h *httpHandlers) requestedSuites(r *http.Request) (selected CheckSuites, unknown []string) {
+	names := r.URL.Query()["suite"]
+	if name := r.PathValue("suite"); name != "" {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return *h.checkGroups, unknown
+	}
+	selected = make(CheckSuites)
+	for _, name := range names {
+		suite, ok := (*h.checkGroups)[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
 		}
-		reqHandlerChan <- r
+		selected[name] = suite
 	}
-	return httpRequestHandler
+	return selected, unknown
 }
 
-// runChecks runs checks with logs, and returns number of passed, failed and timedout checks
-func runChecks(runner *Runner, checkGroups *CheckSuites, logger *log.Logger) (passed, failed, timedout int) {
-	checks := runner.RunChecks(*checkGroups)
-	for _, chk := range checks {
-		if chk.Status() != StatusDone {
-			timedout++
-		} else {
-			if chk.Result().IsOK {
-				passed++
-			} else {
-				failed++
+// hasRequiredHeaders returns true if the http request has the required headers, logging the missing ones
+func (h *httpHandlers) hasRequiredHeaders(r *http.Request) bool {
+	for header, value := range h.conf.RequestRequiredHeaders {
+		reqHeader, ok := r.Header[header]
+		if !ok {
+			h.logger.Printf("http request missing required header %s: %s", header, httpRequestAsString(r))
+			return false
+		}
+		if value != "" && reqHeader[0] != value {
+			h.logger.Printf("http request doesn't match required header %s: %s", header, httpRequestAsString(r))
+			return false
+		}
+	}
+	return true
+}
+
+// acceptsJSON returns true if the http request accepts a JSON response
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err == nil && mediaType == "application/json" {
+				return true
 			}
 		}
-		logger.Printf("check %s status %d ok: %v", chk.Name(), chk.Status(), chk.Result().IsOK)
 	}
-	logger.Printf("%v checks done. passed: %v - failed: %v - timedout: %v", len(checks), passed, failed, timedout)
-	return passed, failed, timedout
+	return false
+}
+
+// writeJSONResponse writes the value as a JSON http response body with the status code
+func writeJSONResponse(w http.ResponseWriter, statusCode int, value any, logger *log.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Printf("failed to write json response: %v", err)
+	}
+}
+
+// runChecks runs checks with logs, and returns the report of the checks
+func runChecks(runner *Runner, checkGroups *CheckSuites, logger *log.Logger) Report {
+	checks := runner.RunChecks(*checkGroups)
+	for _, chk := range checks {
+		logger.Printf("check %s status %s ok: %v issues: %v", chk.Name(), chk.Status(), chk.Result().IsOK,
+			chk.Result().Issues)
+	}
+	report := NewReport(checks)
+	totals := report.Totals
+	logger.Printf("%v checks done. passed: %v - failed: %v - timedout: %v - skipped: %v",
+		totals.Total, totals.Passed, totals.Failed, totals.Timedout, totals.Skipped)
+	return report
 }
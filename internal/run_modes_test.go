@@ -0,0 +1,378 @@
+package chkok
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCheckSuites returns check suites with a passing files suite and a failing missing suite
+func newTestCheckSuites(t *testing.T) CheckSuites {
+	suites, err := CheckSuitesFromSpecSuites(ConfCheckSpecSuites{
+		"files": ConfCheckSpecSuite{Checks: []ConfCheckSpec{
+			{Type: "file", Path: "../LICENSE"}, {Type: "dir", Path: "../cmd"},
+		}},
+		"missing": ConfCheckSpecSuite{Checks: []ConfCheckSpec{{Type: "file", Path: "/no/such/path/exists"}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create check suites: %v", err)
+	}
+	return suites
+}
+
+// newTestHTTPHandlers returns http handlers for the check suites, buffering processed requests
+func newTestHTTPHandlers(conf *ConfRunner, suites *CheckSuites) *httpHandlers {
+	logger := log.New(io.Discard, "", log.Lshortfile)
+	reqHandlerChan := make(chan *http.Request, 10)
+	return newHTTPHandlers(reqHandlerChan, conf, suites, logger)
+}
+
+// serveTestHTTPRequest serves the request with new http handlers, returning the response
+func serveTestHTTPRequest(conf *ConfRunner, suites *CheckSuites, r *http.Request) *http.Response {
+	recorder := httptest.NewRecorder()
+	newTestHTTPHandlers(conf, suites).serveMux().ServeHTTP(recorder, r)
+	return recorder.Result()
+}
+
+func TestHTTPRequestHandlerPlainText(t *testing.T) {
+	conf := GetBaseConfRunner()
+	suites := newTestCheckSuites(t)
+	resp := serveTestHTTPRequest(&conf, &suites, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("want status code %v got %v", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if string(body) != *conf.ResponseFailed {
+		t.Errorf("want body %q got %q", *conf.ResponseFailed, body)
+	}
+}
+
+func TestHTTPRequestHandlerJSON(t *testing.T) {
+	conf := GetBaseConfRunner()
+	suites := newTestCheckSuites(t)
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept", "text/html;q=0.9, application/json")
+	resp := serveTestHTTPRequest(&conf, &suites, req)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("want status code %v got %v", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("want json content type, got %q", got)
+	}
+	var report SuitesReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("want json body, got err %v", err)
+	}
+	if report.IsOK || report.Totals.Total != 3 || report.Totals.Failed != 1 {
+		t.Errorf("want 1 failed of 3 checks, got %+v", report.Totals)
+	}
+	if len(report.Suites) != 2 {
+		t.Fatalf("want 2 suites, got %+v", report.Suites)
+	}
+	files, missing := report.Suites[0], report.Suites[1]
+	if files.Name != "files" || !files.IsOK || len(files.Checks) != 2 {
+		t.Errorf("want files suite ok with 2 checks, got %+v", files)
+	}
+	if missing.Name != "missing" || missing.IsOK || len(missing.Checks[0].Issues) != 1 {
+		t.Errorf("want missing suite not ok with an issue, got %+v", missing)
+	}
+}
+
+func TestHTTPRequestHandlerJSONOutputConf(t *testing.T) {
+	conf := GetBaseConfRunner()
+	output := OutputJSON
+	conf.Output = &output
+	suites := newTestCheckSuites(t)
+	delete(suites, "missing")
+	resp := serveTestHTTPRequest(&conf, &suites, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want status code %v got %v", http.StatusOK, resp.StatusCode)
+	}
+	var report SuitesReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("want json body, got err %v", err)
+	}
+	if !report.IsOK || report.Totals.Passed != 2 {
+		t.Errorf("want 2 passed checks, got %+v", report.Totals)
+	}
+}
+
+func TestHTTPRequestHandlerLimitsConcurrentRequests(t *testing.T) {
+	conf := GetBaseConfRunner()
+	suites := newTestCheckSuites(t)
+	handlers := newTestHTTPHandlers(&conf, &suites)
+	handlers.runningRequests.Add(1) // a request is already running
+
+	recorder := httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("want status code %v got %v", http.StatusServiceUnavailable, recorder.Code)
+	}
+	if got := handlers.rejectedRequests.Load(); got != 1 {
+		t.Errorf("want 1 rejected request, got %v", got)
+	}
+
+	handlers.runningRequests.Add(-1)
+	recorder = httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("want status code %v after running request is done, got %v", http.StatusInternalServerError, recorder.Code)
+	}
+	if got := handlers.runningRequests.Load(); got != 0 {
+		t.Errorf("want no running requests, got %v", got)
+	}
+}
+
+func TestHTTPRequestHandlerRequiredHeaders(t *testing.T) {
+	conf := GetBaseConfRunner()
+	conf.RequestRequiredHeaders = map[string]string{"X-Required": "yes"}
+	suites := newTestCheckSuites(t)
+
+	testCases := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Missing header", "", http.StatusBadRequest, *conf.ResponseInvalidRequest},
+		{"Header mismatch", "no", http.StatusBadRequest, *conf.ResponseInvalidRequest},
+		{"Header match", "yes", http.StatusInternalServerError, *conf.ResponseFailed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tc.header != "" {
+				req.Header.Set("X-Required", tc.header)
+			}
+			resp := serveTestHTTPRequest(&conf, &suites, req)
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tc.wantStatus || string(body) != tc.wantBody {
+				t.Errorf("want status code %v with body %q, got %v %q", tc.wantStatus, tc.wantBody, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestHTTPMetricsHandler(t *testing.T) {
+	conf := GetBaseConfRunner()
+	suites := newTestCheckSuites(t)
+	handlers := newTestHTTPHandlers(&conf, &suites)
+	handlers.rejectedRequests.Add(2)
+	handlers.serveMux().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	recorder := httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("want status code %v got %v", http.StatusOK, recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != PrometheusContentType {
+		t.Errorf("want prometheus content type, got %q", got)
+	}
+	body := recorder.Body.String()
+	wantLines := []string{
+		`chkok_check_ok{suite="files",name="file:../LICENSE"} 1`,
+		`chkok_check_ok{suite="missing",name="file:/no/such/path/exists"} 0`,
+		`chkok_check_status{suite="files",name="dir:../cmd",status="done"} 1`,
+		"chkok_http_requests_served_total 2",
+		"chkok_http_requests_rejected_total 2",
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("want metrics to contain %q, got %v", line, body)
+		}
+	}
+}
+
+func TestHTTPMetricsHandlerRunsChecks(t *testing.T) {
+	conf := GetBaseConfRunner()
+	path := filepath.Join(t.TempDir(), "ready")
+	if err := os.WriteFile(path, []byte("ok"), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	suites, err := CheckSuitesFromSpecSuites(ConfCheckSpecSuites{
+		"ready": ConfCheckSpecSuite{Checks: []ConfCheckSpec{{Type: "file", Path: path}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create check suites: %v", err)
+	}
+	handlers := newTestHTTPHandlers(&conf, &suites)
+	metric := fmt.Sprintf(`chkok_check_ok{suite="ready",name="file:%v"}`, path)
+
+	recorder := httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	if body := recorder.Body.String(); !strings.Contains(body, metric+" 1\n") {
+		t.Errorf("want metrics to contain %q, got %v", metric+" 1", body)
+	}
+	if err = os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	recorder = httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	if body := recorder.Body.String(); !strings.Contains(body, metric+" 0\n") {
+		t.Errorf("want metrics of the next scrape to contain %q, got %v", metric+" 0", body)
+	}
+
+	handlers.runningRequests.Add(1) // scrapes running the checks are limited like other requests
+	recorder = httptest.NewRecorder()
+	handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	handlers.runningRequests.Add(-1)
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("want status code %v reaching max concurrent requests, got %v", http.StatusServiceUnavailable,
+			recorder.Code)
+	}
+}
+
+func TestHTTPRequestHandlerSelectsSuites(t *testing.T) {
+	conf := GetBaseConfRunner()
+	suites := newTestCheckSuites(t)
+
+	testCases := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"All suites", "/", http.StatusInternalServerError, *conf.ResponseFailed},
+		{"Suite path", "/suites/files", http.StatusOK, *conf.ResponseOK},
+		{"Failing suite path", "/suites/missing", http.StatusInternalServerError, *conf.ResponseFailed},
+		{"Unknown suite path", "/suites/unknown", http.StatusNotFound, *conf.ResponseNotFound},
+		{"Suite query", "/?suite=files", http.StatusOK, *conf.ResponseOK},
+		{"Multiple suites query", "/?suite=files&suite=missing", http.StatusInternalServerError, *conf.ResponseFailed},
+		{"Unknown suite query", "/?suite=files&suite=unknown", http.StatusNotFound, *conf.ResponseNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := serveTestHTTPRequest(&conf, &suites, httptest.NewRequest(http.MethodGet, tc.target, http.NoBody))
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tc.wantStatus || string(body) != tc.wantBody {
+				t.Errorf("want status code %v with body %q, got %v %q", tc.wantStatus, tc.wantBody, resp.StatusCode, body)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/suites/files", http.NoBody)
+	req.Header.Set("Accept", "application/json")
+	resp := serveTestHTTPRequest(&conf, &suites, req)
+	defer resp.Body.Close()
+	var report SuitesReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("want json body, got err %v", err)
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Name != "files" {
+		t.Errorf("want only files suite in report, got %+v", report.Suites)
+	}
+}
+
+func TestHTTPRequestHandlerScheduledRuns(t *testing.T) {
+	conf := GetBaseConfRunner()
+	interval, maxResultAge := time.Hour, time.Minute
+	conf.Interval, conf.MaxResultAge = &interval, &maxResultAge
+	suites := newTestCheckSuites(t)
+	handlers := newTestHTTPHandlers(&conf, &suites)
+
+	serve := func(target string) (*http.Response, string) {
+		recorder := httptest.NewRecorder()
+		handlers.serveMux().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+		resp := recorder.Result()
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := serve("/")
+	if resp.StatusCode != http.StatusServiceUnavailable || body != *conf.ResponseUnavailable {
+		t.Errorf("want unavailable before the first run, got %v %q", resp.StatusCode, body)
+	}
+	if len(handlers.reqHandlerChan) != 1 || handlers.servedRequests.Load() != 0 {
+		t.Errorf("want the request processed but not served before the first run, got %v processed %v served",
+			len(handlers.reqHandlerChan), handlers.servedRequests.Load())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run once
+	handlers.runScheduled(ctx)
+	if resp, body = serve("/"); resp.StatusCode != http.StatusInternalServerError || body != *conf.ResponseFailed {
+		t.Errorf("want failed from the scheduled run, got %v %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get(ResultAgeHeader) == "" {
+		t.Errorf("want result age header, got headers %v", resp.Header)
+	}
+	if resp, body = serve("/suites/files"); resp.StatusCode != http.StatusOK || body != *conf.ResponseOK {
+		t.Errorf("want ok from the scheduled run of the suite, got %v %q", resp.StatusCode, body)
+	}
+
+	latest := handlers.latestReport.Load()
+	latest.completed = time.Now().Add(-2 * time.Minute)
+	resp, body = serve("/suites/files")
+	if resp.StatusCode != http.StatusServiceUnavailable || body != *conf.ResponseStale {
+		t.Errorf("want stale after max result age, got %v %q", resp.StatusCode, body)
+	}
+	if age, err := strconv.ParseFloat(resp.Header.Get(ResultAgeHeader), 64); err != nil || age < 120 {
+		t.Errorf("want result age header of at least 120 seconds, got %q", resp.Header.Get(ResultAgeHeader))
+	}
+	if len(handlers.reqHandlerChan) != 4 || handlers.servedRequests.Load() != 2 {
+		t.Errorf("want 4 processed requests with 2 served reports, got %v processed %v served",
+			len(handlers.reqHandlerChan), handlers.servedRequests.Load())
+	}
+}
+
+func TestHTTPRequestHandlerWarnings(t *testing.T) {
+	conf := GetBaseConfRunner()
+	warnMaxFileCount := 0
+	suites, err := CheckSuitesFromSpecSuites(ConfCheckSpecSuites{
+		"files": ConfCheckSpecSuite{Checks: []ConfCheckSpec{
+			{Type: "file", Path: "../LICENSE"},
+			{Type: "dir", Path: "../cmd", WarnMaxFileCount: &warnMaxFileCount},
+			{Type: "file", Path: "/no/such/path/exists", Severity: "warning"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create check suites: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	resp := serveTestHTTPRequest(&conf, &suites, req)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != *conf.ResponseWarning {
+		t.Errorf("want status %v and warning body %q, got %v %q", http.StatusOK, *conf.ResponseWarning,
+			resp.StatusCode, body)
+	}
+	if got := resp.Header.Get(WarningsHeader); got != "2" {
+		t.Errorf("want warnings header of 2 warned checks, got %q", got)
+	}
+
+	warningStatus := http.StatusAccepted
+	conf.ResponseWarningStatus = &warningStatus
+	req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept", "application/json")
+	resp = serveTestHTTPRequest(&conf, &suites, req)
+	defer resp.Body.Close()
+	if resp.StatusCode != warningStatus || resp.Header.Get(WarningsHeader) != "2" {
+		t.Errorf("want configured warning status %v with warnings header, got %v %v", warningStatus,
+			resp.StatusCode, resp.Header)
+	}
+	var report SuitesReport
+	if err = json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("want json body, got err %v", err)
+	}
+	if !report.IsOK || report.Totals != (ReportTotals{Total: 3, Passed: 1, Warned: 2}) {
+		t.Errorf("want ok report with 2 warned checks, got %+v", report)
+	}
+}
@@ -1,50 +1,76 @@
 package chkok
 
 import (
+	"errors"
 	"log"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
 
+// ErrSkippedAfterFailure is the issue of checks skipped after a failed check in a suite
+var ErrSkippedAfterFailure = errors.New("skipped after an earlier check in the suite failed")
+
 // Runner runs all the checks logging details
 type Runner struct {
 	Log     *log.Logger
 	Timeout time.Duration
 }
 
-// RunChecks runs all the checks in the suite and returns slice of check
+// RunChecks runs the suites in parallel, and checks of each suite sequentially.
+// Returns slice of all checks, ordered by suite name and position in the suite.
 func (r *Runner) RunChecks(suites CheckSuites) []Check {
-	var now, deadline time.Time
-	deadline = time.Now().Add(r.Timeout)
+	deadline := time.Now().Add(r.Timeout)
+	names := slices.Sorted(maps.Keys(suites))
 	var checks []Check
+	for _, name := range names {
+		checks = append(checks, suites[name].Checks...)
+	}
+	r.Log.Printf("going to run %d checks in %d suites", len(checks), len(names))
 	var wg sync.WaitGroup
-	// TODO: implement check groups sequencial runs, wait for each item
-	// in a group before submitting the next one
-	for _, groupChecks := range suites {
-		checks = append(checks, groupChecks...)
+	for _, name := range names {
+		suite := suites[name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runSuite(name, &suite, deadline)
+		}()
 	}
-	r.Log.Printf("going to run %d checks", len(checks))
-	var result []Check
-	for index := range checks {
-		chk := checks[index]
-		result = append(result, chk)
-		now = time.Now()
-		if now.Before(deadline) {
-			// adjust timeout for timed checks based on remaining timeout of the runner
-			remaining := deadline.Sub(now)
-			if timedCheck, ok := chk.(TimedCheck); ok {
-				if timedCheck.GetTimeout() > remaining {
-					timedCheck.SetTimeout(remaining)
-				}
+	wg.Wait()
+	return checks
+}
+
+// runSuite runs checks of the suite one after another, until the deadline is reached
+func (r *Runner) runSuite(name string, suite *CheckSuite, deadline time.Time) {
+	var failed bool
+	for _, chk := range suite.Checks {
+		if failed && suite.StopOnFailure {
+			if hooks, ok := chk.(checkHooks); ok {
+				hooks.skip(ErrSkippedAfterFailure)
 			}
-			// schedule the check to run
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				chk.Run()
-			}()
+			continue
+		}
+		now := time.Now()
+		if !now.Before(deadline) {
+			r.Log.Printf("runner timedout before running check %s in suite %s", chk.Name(), name)
+			return
+		}
+		// adjust timeout for timed checks based on remaining timeout of the runner
+		remaining := deadline.Sub(now)
+		if timedCheck, ok := chk.(TimedCheck); ok {
+			if timedCheck.GetTimeout() > remaining {
+				timedCheck.SetTimeout(remaining)
+			}
+		}
+		start := time.Now()
+		result := chk.Run()
+		if hooks, ok := chk.(checkHooks); ok {
+			hooks.setDuration(time.Since(start))
+			result = hooks.applySeverity()
+		}
+		if !result.IsOK {
+			failed = true
 		}
 	}
-	wg.Wait()
-	return result
 }
@@ -3,6 +3,7 @@ package chkok
 import (
 	"io"
 	"log"
+	"net"
 	"testing"
 	"time"
 )
@@ -11,7 +12,7 @@ func TestTimedoutRunnerWontSubmitChecks(t *testing.T) {
 	logger := log.New(io.Discard, "", log.Lshortfile)
 	timeout, _ := time.ParseDuration("0s")
 	checks := make(CheckSuites)
-	checks["default"] = []Check{NewCheckFile("examples"), NewCheckDial()}
+	checks["default"] = CheckSuite{Checks: []Check{NewCheckFile("examples"), NewCheckDial()}}
 	runner := Runner{Log: logger, Timeout: timeout}
 	results := runner.RunChecks(checks)
 	checkDial := results[1]
@@ -27,10 +28,109 @@ func TestTimedoutRunnerAdjustsTimeouts(t *testing.T) {
 	checks := make(CheckSuites)
 	checkDial := NewCheckDial()
 	checkDial.SetTimeout(duration10)
-	checks["default"] = []Check{checkDial}
+	checks["default"] = CheckSuite{Checks: []Check{checkDial}}
 	runner := Runner{Log: logger, Timeout: timeout}
 	runner.RunChecks(checks)
 	if checkDial.GetTimeout() > timeout {
 		t.Errorf("wanted check dial's timeout adjusted to 1s, got %v", checkDial.GetTimeout())
 	}
 }
+
+func TestRunnerLimitsDialWithoutTimeout(t *testing.T) {
+	logger := log.New(io.Discard, "", log.Lshortfile)
+	silent := make(chan struct{})
+	t.Cleanup(func() { close(silent) })
+	tcpAddress := serveTestTCP(t, func(conn net.Conn) {
+		<-silent // accept but never reply
+	})
+	specs := []ConfCheckSpec{
+		{Type: "dial", Network: "tcp", Address: tcpAddress, Send: `PING\r\n`, Expect: "+PONG"},
+		{Type: "dial", Network: "udp", Address: serveTestUDPEcho(t, false), Send: "ping"},
+	}
+	for _, spec := range specs {
+		checkDial, err := CheckDialFromSpec(&spec)
+		if err != nil {
+			t.Fatalf("check dial from spec want no err, got %v", err)
+		}
+		checks := make(CheckSuites)
+		checks["default"] = CheckSuite{Checks: []Check{checkDial}}
+		runner := Runner{Log: logger, Timeout: 300 * time.Millisecond}
+		start := time.Now()
+		runner.RunChecks(checks)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("want check dial %v to a silent peer limited by the runner timeout, took %v", spec.Network, elapsed)
+		}
+		if result := checkDial.Result(); result.IsOK {
+			t.Errorf("want check dial %v to a silent peer not ok, got ok", spec.Network)
+		}
+	}
+}
+
+func TestRunnerRunsSuiteChecksSequentially(t *testing.T) {
+	logger := log.New(io.Discard, "", log.Lshortfile)
+	timeout, _ := time.ParseDuration("5s")
+	checks := make(CheckSuites)
+	checks["b"] = CheckSuite{Checks: []Check{NewCheckFile("/no/such/path/exists"), NewCheckFile("../LICENSE")}}
+	checks["a"] = CheckSuite{Checks: []Check{NewCheckFile("../examples")}}
+	runner := Runner{Log: logger, Timeout: timeout}
+	results := runner.RunChecks(checks)
+	if len(results) != 3 {
+		t.Fatalf("want 3 checks, got %v", len(results))
+	}
+	wantNames := []string{"any:../examples", "any:/no/such/path/exists", "any:../LICENSE"}
+	for index, chk := range results {
+		if chk.Name() != wantNames[index] {
+			t.Errorf("want check %v to be %v, got %v", index, wantNames[index], chk.Name())
+		}
+		if chk.Status() != StatusDone {
+			t.Errorf("want check %v status done, got %v", chk.Name(), chk.Status())
+		}
+	}
+}
+
+func TestRunnerStopsSuiteOnFailure(t *testing.T) {
+	logger := log.New(io.Discard, "", log.Lshortfile)
+	timeout, _ := time.ParseDuration("5s")
+	checks := make(CheckSuites)
+	checks["stop"] = CheckSuite{
+		Checks:        []Check{NewCheckFile("../LICENSE"), NewCheckFile("/no/such/path/exists"), NewCheckFile("../LICENSE")},
+		StopOnFailure: true,
+	}
+	checks["continue"] = CheckSuite{
+		Checks: []Check{NewCheckFile("/no/such/path/exists"), NewCheckFile("../LICENSE")},
+	}
+	runner := Runner{Log: logger, Timeout: timeout}
+	runner.RunChecks(checks)
+
+	skipped := checks["stop"].Checks[2]
+	if skipped.Status() != StatusSkipped {
+		t.Errorf("want check after failure skipped, got status %v", skipped.Status())
+	}
+	if skipped.Result().IsOK {
+		t.Errorf("want skipped check not ok, got ok")
+	}
+	if got := checks["stop"].Checks[0].Status(); got != StatusDone {
+		t.Errorf("want check before failure done, got status %v", got)
+	}
+	if got := checks["continue"].Checks[1]; got.Status() != StatusDone || !got.Result().IsOK {
+		t.Errorf("want check after failure run without stop on failure, got status %v", got.Status())
+	}
+}
+
+func TestRunnerAppliesWarningSeverity(t *testing.T) {
+	logger := log.New(io.Discard, "", log.Lshortfile)
+	warning := NewCheckFile("/no/such/path/exists")
+	warning.setSeverity(SeverityWarning)
+	checks := make(CheckSuites)
+	checks["stop"] = CheckSuite{Checks: []Check{warning, NewCheckFile("../LICENSE")}, StopOnFailure: true}
+	runner := Runner{Log: logger, Timeout: 5 * time.Second}
+	runner.RunChecks(checks)
+
+	result := warning.Result()
+	if !result.IsOK || len(result.Issues) != 0 || len(result.Warnings) != 1 {
+		t.Errorf("want failed warning severity check ok with 1 warning, got %+v", result)
+	}
+	if got := checks["stop"].Checks[1].Status(); got != StatusDone {
+		t.Errorf("want check after warning run with stop on failure, got status %v", got)
+	}
+}
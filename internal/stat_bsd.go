@@ -0,0 +1,18 @@
+//go:build darwin || freebsd || netbsd
+
+package chkok
+
+import (
+	"syscall"
+	"time"
+)
+
+// statTimes returns the access, modification and status change times of the file stat
+func statTimes(fstat *syscall.Stat_t) (atime, mtime, ctime time.Time) {
+	return time.Unix(fstat.Atimespec.Unix()), time.Unix(fstat.Mtimespec.Unix()), time.Unix(fstat.Ctimespec.Unix())
+}
+
+// statMode returns the mode (file type and permission bits) of the file stat
+func statMode(fstat *syscall.Stat_t) uint32 {
+	return uint32(fstat.Mode)
+}
@@ -0,0 +1,16 @@
+package chkok
+
+import (
+	"syscall"
+	"time"
+)
+
+// statTimes returns the access, modification and status change times of the file stat
+func statTimes(fstat *syscall.Stat_t) (atime, mtime, ctime time.Time) {
+	return time.Unix(fstat.Atim.Unix()), time.Unix(fstat.Mtim.Unix()), time.Unix(fstat.Ctim.Unix())
+}
+
+// statMode returns the mode (file type and permission bits) of the file stat
+func statMode(fstat *syscall.Stat_t) uint32 {
+	return fstat.Mode
+}
@@ -0,0 +1,8 @@
+package chkok
+
+import "syscall"
+
+// statfsAvail returns the number of blocks available to unprivileged users of the filesystem stat
+func statfsAvail(stat *syscall.Statfs_t) uint64 {
+	return uint64(max(stat.Bavail, 0)) // negative when the reserved blocks are in use
+}
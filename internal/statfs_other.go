@@ -0,0 +1,10 @@
+//go:build linux || darwin
+
+package chkok
+
+import "syscall"
+
+// statfsAvail returns the number of blocks available to unprivileged users of the filesystem stat
+func statfsAvail(stat *syscall.Statfs_t) uint64 {
+	return stat.Bavail
+}
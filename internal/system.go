@@ -8,6 +8,9 @@ import (
 // ExOK exit code for successful run
 const ExOK = 0
 
+// ExWarning exit code when checks passed with warnings
+const ExWarning = 1
+
 // ExDataErr exit code for invalid data
 const ExDataErr = 65
 